@@ -49,6 +49,12 @@ func GetResource(path string) []byte {
 	return r
 }
 
+// GetAudioMetadata returns the metadata for the audio path without an extension.
+// GetAudioMetadata returns nil if the metadata doesn't exist.
+func GetAudioMetadata(path string) *data.AssetMetadata {
+	return theAssets.metadata[path+"_metadata.json"]
+}
+
 func GetMetadata(imageName string) *data.AssetMetadata {
 	path := "images/" + imageName + "_metadata.json"
 	m, ok := theAssets.metadata[path]
@@ -17,15 +17,15 @@ package audio
 import (
 	"bytes"
 	"fmt"
-	"io/ioutil"
+	"time"
 
 	eaudio "github.com/hajimehoshi/ebiten/audio"
 	"github.com/hajimehoshi/ebiten/audio/mp3"
 	"github.com/hajimehoshi/ebiten/audio/vorbis"
-	"github.com/hajimehoshi/ebiten/audio/wav"
 	"github.com/hajimehoshi/oggloop"
 
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/assets"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/interpolation"
 )
 
@@ -47,14 +47,21 @@ func Stop() {
 	theAudio.Stop()
 }
 
-func PlaySE(name string, volume float64) {
-	theAudio.PlaySE(name, volume)
-}
-
 func PlayBGM(name string, volume float64, fadeTimeInFrames int) {
 	theAudio.PlayBGM(name, volume, fadeTimeInFrames)
 }
 
+// PlayBGMFromSavedPosition plays the BGM from the position where the BGM was played last time.
+// This is useful to return to a field BGM after a battle or a cutscene.
+func PlayBGMFromSavedPosition(name string, volume float64, fadeTimeInFrames int) {
+	theAudio.PlayBGMFromSavedPosition(name, volume, fadeTimeInFrames)
+}
+
+// PlayBGMAt plays the BGM from the given position.
+func PlayBGMAt(name string, volume float64, fadeTimeInFrames int, position time.Duration) {
+	theAudio.PlayBGMAt(name, volume, fadeTimeInFrames, position)
+}
+
 func PlayingBGMName() string {
 	return theAudio.playingBGMName
 }
@@ -63,6 +70,13 @@ func PlayingBGMVolume() float64 {
 	return theAudio.bgmVolume.Dst()
 }
 
+func PlayingBGMPosition() time.Duration {
+	if theAudio.playing == nil {
+		return 0
+	}
+	return theAudio.playing.Current()
+}
+
 func StopBGM(fadeTimeInFrames int) {
 	theAudio.StopBGM(fadeTimeInFrames)
 }
@@ -75,16 +89,34 @@ func PauseBGM() {
 	theAudio.PauseBGM()
 }
 
+// fadingBGM is a BGM fading out while another BGM is fading in.
+type fadingBGM struct {
+	name   string
+	player *eaudio.Player
+	volume interpolation.I
+}
+
 type audio struct {
 	context        *eaudio.Context
 	players        map[string]*eaudio.Player
-	sePlayers      map[*eaudio.Player]struct{}
+	seChannels     []*seChannel
 	playing        *eaudio.Player
 	playingBGMName string
+	fadingBGMs     []*fadingBGM
+	bgmPositions   map[string]time.Duration
+	bgsChannels    map[int]*bgsChannel
+	voicePlayer    *eaudio.Player
+	voiceName      string
+
+	pcmCache  *pcmCache
+	preloadCh chan string
 
-	wavCache map[string][]byte
+	nextSEHandle       int
+	seLastPlayedFrames map[string]int
+	frame              int
 
 	bgmVolume interpolation.I
+	ducking   ducking
 
 	toStopBGM bool
 	paused    bool
@@ -97,12 +129,18 @@ func newAudio() (*audio, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &audio{
-		context:   context,
-		players:   map[string]*eaudio.Player{},
-		sePlayers: map[*eaudio.Player]struct{}{},
-		wavCache:  map[string][]byte{},
-	}, nil
+	a := &audio{
+		context:      context,
+		players:      map[string]*eaudio.Player{},
+		bgmPositions: map[string]time.Duration{},
+		bgsChannels:  map[int]*bgsChannel{},
+		pcmCache:     newPCMCache(pcmCacheBudget),
+		preloadCh:    make(chan string, preloadQueueSize),
+
+		seLastPlayedFrames: map[string]int{},
+	}
+	go a.preloadLoop()
+	return a, nil
 }
 
 func (a *audio) Update() error {
@@ -113,27 +151,25 @@ func (a *audio) Update() error {
 	if !a.paused {
 		a.bgmVolume.Update()
 	}
+	a.updateVoice()
+	a.ducking.update(a.isVoicePlaying())
 	if a.playing != nil {
-		a.playing.SetVolume(a.bgmVolume.Current() * volumeBias * bgmVolumeBias)
+		a.playing.SetVolume(a.bgmVolume.Current() * a.bgmVolumeRate())
 	}
 	if a.toStopBGM && !a.bgmVolume.IsChanging() {
+		a.bgmPositions[a.playingBGMName] = a.playing.Current()
 		a.playing.Close()
 		delete(a.players, a.playingBGMName)
 		a.playing = nil
 		a.playingBGMName = ""
 		a.toStopBGM = false
 	}
-
-	closed := []*eaudio.Player{}
-	for p := range a.sePlayers {
-		if !p.IsPlaying() {
-			p.Close()
-			closed = append(closed, p)
-		}
-	}
-	for _, p := range closed {
-		delete(a.sePlayers, p)
+	if err := a.updateFadingBGMs(); err != nil {
+		a.err = err
+		return err
 	}
+	a.updateBGSs()
+	a.updateSEs()
 	return nil
 }
 
@@ -142,14 +178,70 @@ func (a *audio) Stop() {
 		return
 	}
 	StopBGM(0)
-	for p := range a.sePlayers {
-		p.Pause()
-		// These SEs are no longer used, so positions should not be cared.
+	for _, f := range a.fadingBGMs {
+		f.player.Pause()
+		f.player.Rewind()
+	}
+	a.fadingBGMs = nil
+	for ch := range a.bgsChannels {
+		a.StopBGS(ch, 0)
+	}
+	a.stopVoice()
+	a.ducking.reset()
+	for _, c := range a.seChannels {
+		c.player.Close()
+	}
+	a.seChannels = nil
+}
+
+// loopPoints returns the loop start and the loop length in samples.
+// The asset metadata m is prioritized over the given default values, which come from e.g. ogg comments.
+// size is the size of the stream in bytes.
+func loopPoints(m *data.AssetMetadata, size int64, defaultStart, defaultLength int64) (int64, int64) {
+	if m == nil || (m.LoopStart == 0 && m.LoopEnd == 0) {
+		return defaultStart, defaultLength
+	}
+	end := m.LoopEnd
+	if end == 0 {
+		end = size / bytesPerFrame
 	}
-	a.sePlayers = map[*eaudio.Player]struct{}{}
+	if end <= m.LoopStart {
+		return defaultStart, defaultLength
+	}
+	return m.LoopStart, end - m.LoopStart
+}
+
+func LoopPointsForTesting(m *data.AssetMetadata, size int64, defaultStart, defaultLength int64) (int64, int64) {
+	return loopPoints(m, size, defaultStart, defaultLength)
+}
+
+// newLoopStream returns a stream looping from start to start+length in samples.
+// The part before start is played only once.
+// If length is 0, the whole stream is looped.
+func newLoopStream(s eaudio.ReadSeekCloser, size int64, start, length int64) eaudio.ReadSeekCloser {
+	if length == 0 {
+		return eaudio.NewInfiniteLoop(s, size)
+	}
+	return eaudio.NewInfiniteLoopWithIntro(s, start*bytesPerFrame, length*bytesPerFrame)
 }
 
+func (a *audio) newPlayerFromPCM(path string, d *decodedAudio, loop bool) (*eaudio.Player, error) {
+	s := eaudio.BytesReadSeekCloser(d.pcm)
+	if loop {
+		size := int64(len(d.pcm))
+		start, length := loopPoints(assets.GetAudioMetadata(path), size, d.loopStart, d.loopLength)
+		return eaudio.NewPlayer(a.context, newLoopStream(s, size, start, length))
+	}
+	return eaudio.NewPlayer(a.context, s)
+}
+
+// getPlayer returns a new player for the path without an extension.
+// Preloaded audio is used if exists. Otherwise, mp3 and ogg are decoded while playing.
 func (a *audio) getPlayer(path string, loop bool) (*eaudio.Player, error) {
+	if d, ok := a.pcmCache.get(path); ok {
+		return a.newPlayerFromPCM(path, d, loop)
+	}
+
 	mp3Path := path + ".mp3"
 	oggPath := path + ".ogg"
 	wavPath := path + ".wav"
@@ -160,7 +252,8 @@ func (a *audio) getPlayer(path string, loop bool) (*eaudio.Player, error) {
 			return nil, fmt.Errorf("audio: decode error: %s, %v", mp3Path, err)
 		}
 		if loop {
-			return eaudio.NewPlayer(a.context, eaudio.NewInfiniteLoop(s, s.Length()))
+			start, length := loopPoints(assets.GetAudioMetadata(path), s.Length(), 0, 0)
+			return eaudio.NewPlayer(a.context, newLoopStream(s, s.Length(), start, length))
 		}
 		return eaudio.NewPlayer(a.context, s)
 	}
@@ -176,98 +269,145 @@ func (a *audio) getPlayer(path string, loop bool) (*eaudio.Player, error) {
 			return nil, fmt.Errorf("audio: decode error: %s, %v", oggPath, err)
 		}
 		if loop {
-			var stream eaudio.ReadSeekCloser
-			if start == 0 && length == 0 {
-				stream = eaudio.NewInfiniteLoop(s, s.Length())
-			} else {
-				// 4 = 2 [channels] * 2 [bytes]
-				stream = eaudio.NewInfiniteLoopWithIntro(s, start*4, length*4)
-			}
-			return eaudio.NewPlayer(a.context, stream)
+			start, length := loopPoints(assets.GetAudioMetadata(path), s.Length(), start, length)
+			return eaudio.NewPlayer(a.context, newLoopStream(s, s.Length(), start, length))
 		}
 		return eaudio.NewPlayer(a.context, s)
 	}
 
 	if assets.Exists(wavPath) {
-		if _, ok := a.wavCache[wavPath]; !ok {
-			bin := assets.GetResource(wavPath)
-			s, err := wav.Decode(a.context, eaudio.BytesReadSeekCloser(bin))
-			if err != nil {
-				return nil, fmt.Errorf("audio: decode error: %s, %v", wavPath, err)
-			}
-			bs, err := ioutil.ReadAll(s)
-			if err != nil {
-				return nil, err
-			}
-			a.wavCache[wavPath] = bs
-		}
-		bs := a.wavCache[wavPath]
-		s := eaudio.BytesReadSeekCloser(bs)
-		if loop {
-			return eaudio.NewPlayer(a.context, eaudio.NewInfiniteLoop(s, int64(len(bs))))
+		d, err := a.loadPCM(path)
+		if err != nil {
+			return nil, err
 		}
-		return eaudio.NewPlayer(a.context, s)
+		return a.newPlayerFromPCM(path, d, loop)
 	}
 
 	return nil, fmt.Errorf("audio: %s not found", path)
 }
 
-func (a *audio) PlaySE(name string, volume float64) {
-	if a.err != nil {
-		return
-	}
-	p, err := a.getPlayer("audio/se/"+name, false)
-	if err != nil {
-		a.err = err
-		return
-	}
-	p.SetVolume(volume * volumeBias * seVolumeBias)
-	p.Play()
-	a.sePlayers[p] = struct{}{}
+func (a *audio) PlayBGM(name string, volume float64, fadeTimeInFrames int) {
+	a.playBGM(name, volume, fadeTimeInFrames, 0)
 }
 
-func (a *audio) PlayBGM(name string, volume float64, fadeTimeInFrames int) {
+func (a *audio) PlayBGMFromSavedPosition(name string, volume float64, fadeTimeInFrames int) {
+	a.playBGM(name, volume, fadeTimeInFrames, a.bgmPositions[name])
+}
+
+func (a *audio) PlayBGMAt(name string, volume float64, fadeTimeInFrames int, position time.Duration) {
+	a.playBGM(name, volume, fadeTimeInFrames, position)
+}
+
+// playBGM plays the BGM. If another BGM is playing, the two BGMs are crossfaded in fadeTimeInFrames.
+// position is used only when the BGM is not playing yet.
+func (a *audio) playBGM(name string, volume float64, fadeTimeInFrames int, position time.Duration) {
 	if a.err != nil {
 		return
 	}
 
 	a.toStopBGM = false
 	a.paused = false
-	a.bgmVolume.Set(volume, fadeTimeInFrames)
 
-	p, ok := a.players[name]
-	if !ok {
-		player, err := a.getPlayer("audio/bgm/"+name, true)
-		if err != nil {
-			a.err = err
-			return
-		}
-		a.players[name] = player
-		p = player
-	}
-	if a.playingBGMName == name {
-		p.SetVolume(a.bgmVolume.Current() * volumeBias * bgmVolumeBias)
-		p.Play()
+	if a.playingBGMName == name && a.playing != nil {
+		a.bgmVolume.Set(volume, fadeTimeInFrames)
+		a.playing.SetVolume(a.bgmVolume.Current() * a.bgmVolumeRate())
+		a.playing.Play()
 		return
 	}
+
 	if a.playing != nil {
-		a.playing.Pause()
-		if err := a.playing.Rewind(); err != nil {
+		a.bgmPositions[a.playingBGMName] = a.playing.Current()
+		if fadeTimeInFrames > 0 {
+			f := &fadingBGM{
+				name:   a.playingBGMName,
+				player: a.playing,
+			}
+			f.volume.Set(a.bgmVolume.Current(), 0)
+			f.volume.Set(0, fadeTimeInFrames)
+			a.fadingBGMs = append(a.fadingBGMs, f)
+		} else {
+			a.playing.Pause()
+			if err := a.playing.Rewind(); err != nil {
+				a.err = err
+				return
+			}
+		}
+		a.playing = nil
+		a.playingBGMName = ""
+	}
+
+	// The new BGM starts from the current volume if the BGM is fading out, or from silence otherwise.
+	startVolume := 0.0
+	var p *eaudio.Player
+	for i, f := range a.fadingBGMs {
+		if f.name != name {
+			continue
+		}
+		startVolume = f.volume.Current()
+		p = f.player
+		a.fadingBGMs = append(a.fadingBGMs[:i], a.fadingBGMs[i+1:]...)
+		break
+	}
+
+	if p == nil {
+		var ok bool
+		p, ok = a.players[name]
+		if !ok {
+			player, err := a.getPlayer("audio/bgm/"+name, true)
+			if err != nil {
+				a.err = err
+				return
+			}
+			a.players[name] = player
+			p = player
+		}
+		if err := p.Seek(position); err != nil {
 			a.err = err
 			return
 		}
 	}
 
+	if fadeTimeInFrames > 0 {
+		a.bgmVolume.Set(startVolume, 0)
+	}
+	a.bgmVolume.Set(volume, fadeTimeInFrames)
+
+	p.SetVolume(a.bgmVolume.Current() * a.bgmVolumeRate())
 	p.Play()
-	p.SetVolume(a.bgmVolume.Current() * volumeBias * bgmVolumeBias)
 	a.playing = p
 	a.playingBGMName = name
 }
 
+func (a *audio) updateFadingBGMs() error {
+	fadings := a.fadingBGMs[:0]
+	for _, f := range a.fadingBGMs {
+		if !a.paused {
+			f.volume.Update()
+		}
+		if f.volume.IsChanging() {
+			f.player.SetVolume(f.volume.Current() * a.bgmVolumeRate())
+			fadings = append(fadings, f)
+			continue
+		}
+		f.player.Pause()
+		if err := f.player.Rewind(); err != nil {
+			return err
+		}
+	}
+	a.fadingBGMs = fadings
+	return nil
+}
+
 func (a *audio) ResumeBGM() {
 	if a.err != nil {
 		return
 	}
+	for _, f := range a.fadingBGMs {
+		f.player.Play()
+	}
+	for _, c := range a.bgsChannels {
+		c.player.Play()
+	}
 	if a.playing == nil {
 		return
 	}
@@ -278,6 +418,12 @@ func (a *audio) PauseBGM() {
 	if a.err != nil {
 		return
 	}
+	for _, f := range a.fadingBGMs {
+		f.player.Pause()
+	}
+	for _, c := range a.bgsChannels {
+		c.player.Pause()
+	}
 	if a.playing == nil {
 		return
 	}
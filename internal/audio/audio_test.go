@@ -0,0 +1,76 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio_test
+
+import (
+	"testing"
+
+	. "github.com/hajimehoshi/rpgsnack-runtime/internal/audio"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+)
+
+func TestLoopPoints(t *testing.T) {
+	const (
+		defaultStart  = 100
+		defaultLength = 200
+
+		// 10000 samples of 16bit stereo
+		size = 40000
+	)
+	cases := []struct {
+		Metadata *data.AssetMetadata
+		Start    int64
+		Length   int64
+	}{
+		{
+			Metadata: nil,
+			Start:    defaultStart,
+			Length:   defaultLength,
+		},
+		{
+			Metadata: &data.AssetMetadata{},
+			Start:    defaultStart,
+			Length:   defaultLength,
+		},
+		{
+			Metadata: &data.AssetMetadata{LoopStart: 1000, LoopEnd: 3000},
+			Start:    1000,
+			Length:   2000,
+		},
+		{
+			Metadata: &data.AssetMetadata{LoopStart: 0, LoopEnd: 500},
+			Start:    0,
+			Length:   500,
+		},
+		{
+			// LoopEnd 0 means the end of the audio.
+			Metadata: &data.AssetMetadata{LoopStart: 1000},
+			Start:    1000,
+			Length:   9000,
+		},
+		{
+			// Invalid loop points are ignored.
+			Metadata: &data.AssetMetadata{LoopStart: 5000, LoopEnd: 4000},
+			Start:    defaultStart,
+			Length:   defaultLength,
+		},
+	}
+	for _, c := range cases {
+		start, length := LoopPointsForTesting(c.Metadata, size, defaultStart, defaultLength)
+		if start != c.Start || length != c.Length {
+			t.Errorf("LoopPointsForTesting(%v, %d, %d, %d): got: %d, %d, want: %d, %d", c.Metadata, size, defaultStart, defaultLength, start, length, c.Start, c.Length)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"sort"
+
+	eaudio "github.com/hajimehoshi/ebiten/audio"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/interpolation"
+)
+
+// BGS is a state of a background sound channel.
+type BGS struct {
+	Channel int
+	Name    string
+	Volume  float64
+}
+
+func PlayBGS(channel int, name string, volume float64, fadeTimeInFrames int) {
+	theAudio.PlayBGS(channel, name, volume, fadeTimeInFrames)
+}
+
+func StopBGS(channel int, fadeTimeInFrames int) {
+	theAudio.StopBGS(channel, fadeTimeInFrames)
+}
+
+func FadeBGS(channel int, volume float64, fadeTimeInFrames int) {
+	theAudio.FadeBGS(channel, volume, fadeTimeInFrames)
+}
+
+func IsFadingBGS(channel int) bool {
+	return theAudio.IsFadingBGS(channel)
+}
+
+// PlayingBGSs returns the states of the playing background sounds in the order of the channels.
+func PlayingBGSs() []BGS {
+	return theAudio.PlayingBGSs()
+}
+
+// bgsChannel is a looping sound channel independent from BGM.
+type bgsChannel struct {
+	name   string
+	player *eaudio.Player
+	volume interpolation.I
+	toStop bool
+}
+
+func (c *bgsChannel) updateVolume(rate float64) {
+	c.player.SetVolume(c.volume.Current() * rate)
+}
+
+func (a *audio) PlayBGS(channel int, name string, volume float64, fadeTimeInFrames int) {
+	if a.err != nil {
+		return
+	}
+
+	if c, ok := a.bgsChannels[channel]; ok {
+		if c.name == name {
+			c.toStop = false
+			c.volume.Set(volume, fadeTimeInFrames)
+			c.updateVolume(a.bgmVolumeRate())
+			c.player.Play()
+			return
+		}
+		c.player.Close()
+		delete(a.bgsChannels, channel)
+	}
+
+	p, err := a.getPlayer("audio/bgs/"+name, true)
+	if err != nil {
+		a.err = err
+		return
+	}
+	c := &bgsChannel{
+		name:   name,
+		player: p,
+	}
+	c.volume.Set(volume, fadeTimeInFrames)
+	c.updateVolume(a.bgmVolumeRate())
+	p.Play()
+	a.bgsChannels[channel] = c
+}
+
+func (a *audio) StopBGS(channel int, fadeTimeInFrames int) {
+	if a.err != nil {
+		return
+	}
+	c, ok := a.bgsChannels[channel]
+	if !ok {
+		return
+	}
+	c.toStop = true
+	c.volume.Set(0, fadeTimeInFrames)
+}
+
+func (a *audio) FadeBGS(channel int, volume float64, fadeTimeInFrames int) {
+	if a.err != nil {
+		return
+	}
+	c, ok := a.bgsChannels[channel]
+	if !ok || c.toStop {
+		return
+	}
+	c.volume.Set(volume, fadeTimeInFrames)
+}
+
+func (a *audio) IsFadingBGS(channel int) bool {
+	c, ok := a.bgsChannels[channel]
+	if !ok {
+		return false
+	}
+	return c.volume.IsChanging()
+}
+
+func (a *audio) PlayingBGSs() []BGS {
+	var bgss []BGS
+	for ch, c := range a.bgsChannels {
+		if c.toStop {
+			continue
+		}
+		bgss = append(bgss, BGS{
+			Channel: ch,
+			Name:    c.name,
+			Volume:  c.volume.Dst(),
+		})
+	}
+	sort.Slice(bgss, func(i, j int) bool {
+		return bgss[i].Channel < bgss[j].Channel
+	})
+	return bgss
+}
+
+func (a *audio) updateBGSs() {
+	for ch, c := range a.bgsChannels {
+		if !a.paused {
+			c.volume.Update()
+		}
+		c.updateVolume(a.bgmVolumeRate())
+		if c.toStop && !c.volume.IsChanging() {
+			c.player.Close()
+			delete(a.bgsChannels, ch)
+		}
+	}
+}
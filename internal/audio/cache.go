@@ -0,0 +1,306 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"sync"
+
+	"github.com/golang/groupcache/lru"
+	eaudio "github.com/hajimehoshi/ebiten/audio"
+	"github.com/hajimehoshi/ebiten/audio/mp3"
+	"github.com/hajimehoshi/ebiten/audio/vorbis"
+	"github.com/hajimehoshi/ebiten/audio/wav"
+	"github.com/hajimehoshi/oggloop"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/assets"
+)
+
+const (
+	// pcmCacheBudget is the maximum total size of decoded PCM in bytes.
+	pcmCacheBudget = 64 * 1024 * 1024
+
+	preloadQueueSize = 64
+)
+
+func PreloadBGM(name string) {
+	theAudio.preload("audio/bgm/" + name)
+}
+
+func PreloadBGS(name string) {
+	theAudio.preload("audio/bgs/" + name)
+}
+
+func PreloadSE(name string) {
+	theAudio.preload("audio/se/" + name)
+}
+
+// decodedAudio is fully decoded audio.
+type decodedAudio struct {
+	pcm []byte
+
+	// loopStart and loopLength are the loop points in samples that come from ogg comments.
+	loopStart  int64
+	loopLength int64
+}
+
+// decodeAudio decodes the audio at the path without an extension.
+func decodeAudio(context *eaudio.Context, path string) (*decodedAudio, error) {
+	d := &decodedAudio{}
+
+	var s eaudio.ReadSeekCloser
+	var err error
+	switch {
+	case assets.Exists(path + ".mp3"):
+		s, err = mp3.Decode(context, eaudio.BytesReadSeekCloser(assets.GetResource(path+".mp3")))
+	case assets.Exists(path + ".ogg"):
+		bin := assets.GetResource(path + ".ogg")
+		d.loopStart, d.loopLength, err = oggloop.Read(bytes.NewReader(bin))
+		if err != nil {
+			return nil, fmt.Errorf("audio: oggloop error: %s, %v", path, err)
+		}
+		s, err = vorbis.Decode(context, eaudio.BytesReadSeekCloser(bin))
+	case assets.Exists(path + ".wav"):
+		s, err = wav.Decode(context, eaudio.BytesReadSeekCloser(assets.GetResource(path+".wav")))
+	default:
+		return nil, fmt.Errorf("audio: %s not found", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("audio: decode error: %s, %v", path, err)
+	}
+	d.pcm, err = ioutil.ReadAll(s)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// pcmCache is an LRU cache of decoded audio limited by the total size.
+// pcmCache is goroutine-safe as preloading is done on another goroutine.
+type pcmCache struct {
+	cache   *lru.Cache
+	size    int
+	budget  int
+	loading map[string]*pcmLoading
+	m       sync.Mutex
+}
+
+// pcmLoading is a state of audio requested to preload.
+type pcmLoading struct {
+	// decoding reports whether the worker goroutine has started decoding.
+	decoding bool
+
+	// done is closed when the preloading is finished or canceled.
+	done chan struct{}
+}
+
+func newPCMCache(budget int) *pcmCache {
+	c := &pcmCache{
+		cache:   lru.New(0),
+		budget:  budget,
+		loading: map[string]*pcmLoading{},
+	}
+	c.cache.OnEvicted = func(key lru.Key, value interface{}) {
+		c.size -= len(value.(*decodedAudio).pcm)
+	}
+	return c
+}
+
+func (c *pcmCache) get(path string) (*decodedAudio, bool) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	d, ok := c.cache.Get(path)
+	if !ok {
+		return nil, false
+	}
+	return d.(*decodedAudio), true
+}
+
+func (c *pcmCache) add(path string, d *decodedAudio) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.finishLoading(path)
+	if len(d.pcm) > c.budget {
+		return
+	}
+	c.cache.Remove(path)
+	c.cache.Add(path, d)
+	c.size += len(d.pcm)
+	for c.size > c.budget {
+		c.cache.RemoveOldest()
+	}
+}
+
+// startLoading marks the path as loading and reports whether the path should be loaded.
+func (c *pcmCache) startLoading(path string) bool {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	if _, ok := c.cache.Get(path); ok {
+		return false
+	}
+	if _, ok := c.loading[path]; ok {
+		return false
+	}
+	c.loading[path] = &pcmLoading{
+		done: make(chan struct{}),
+	}
+	return true
+}
+
+// startDecoding marks the path as being decoded and reports whether the path should still be decoded.
+func (c *pcmCache) startDecoding(path string) bool {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	l, ok := c.loading[path]
+	if !ok {
+		return false
+	}
+	l.decoding = true
+	return true
+}
+
+func (c *pcmCache) cancelLoading(path string) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.finishLoading(path)
+}
+
+func (c *pcmCache) finishLoading(path string) {
+	l, ok := c.loading[path]
+	if !ok {
+		return
+	}
+	close(l.done)
+	delete(c.loading, path)
+}
+
+// wait returns a channel closed when the worker goroutine finishes decoding the path.
+// wait returns nil if the path is not being decoded. If the path is waiting in the queue, the preloading is canceled.
+func (c *pcmCache) wait(path string) <-chan struct{} {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	l, ok := c.loading[path]
+	if !ok {
+		return nil
+	}
+	if !l.decoding {
+		// Decoding it on the caller side is faster than waiting for the other audio in the queue.
+		c.finishLoading(path)
+		return nil
+	}
+	return l.done
+}
+
+// loadPCM returns the decoded audio. If the audio is not cached, loadPCM decodes it synchronously.
+// If the audio is being decoded by the worker goroutine, loadPCM waits for it instead of decoding it twice.
+func (a *audio) loadPCM(path string) (*decodedAudio, error) {
+	if d, ok := a.pcmCache.get(path); ok {
+		return d, nil
+	}
+	if ch := a.pcmCache.wait(path); ch != nil {
+		<-ch
+		if d, ok := a.pcmCache.get(path); ok {
+			return d, nil
+		}
+	}
+	d, err := decodeAudio(a.context, path)
+	if err != nil {
+		return nil, err
+	}
+	a.pcmCache.add(path, d)
+	return d, nil
+}
+
+// preload requests to decode the audio on the worker goroutine.
+func (a *audio) preload(path string) {
+	if !a.pcmCache.startLoading(path) {
+		return
+	}
+	select {
+	case a.preloadCh <- path:
+	default:
+		// The queue is full. Give up preloading.
+		a.pcmCache.cancelLoading(path)
+	}
+}
+
+func (a *audio) preloadLoop() {
+	for path := range a.preloadCh {
+		// The path might be already decoded by loadPCM.
+		if !a.pcmCache.startDecoding(path) {
+			continue
+		}
+		d, err := decodeAudio(a.context, path)
+		if err != nil {
+			// The error is reported when the audio is actually played.
+			a.pcmCache.cancelLoading(path)
+			continue
+		}
+		a.pcmCache.add(path, d)
+	}
+}
+
+// PCMCachedPathsForTesting adds audio of the given sizes to a new cache in order, and returns the paths remaining in the cache.
+func PCMCachedPathsForTesting(budget int, paths []string, sizes []int) []string {
+	c := newPCMCache(budget)
+	for i, p := range paths {
+		c.add(p, &decodedAudio{
+			pcm: make([]byte, sizes[i]),
+		})
+	}
+	var cached []string
+	for _, p := range paths {
+		if _, ok := c.get(p); ok {
+			cached = append(cached, p)
+		}
+	}
+	return cached
+}
+
+// PCMWaitForTesting requests to preload audio and waits for it before or after the worker starts decoding.
+// PCMWaitForTesting reports whether the waiting blocked until the decoded audio was added,
+// and whether the worker would decode the audio after that.
+func PCMWaitForTesting(decodingStarted bool) (blocked bool, workerDecodes bool) {
+	const path = "audio"
+
+	c := newPCMCache(pcmCacheBudget)
+	c.startLoading(path)
+	if decodingStarted {
+		c.startDecoding(path)
+	}
+	if ch := c.wait(path); ch != nil {
+		select {
+		case <-ch:
+			return false, false
+		default:
+		}
+		c.add(path, &decodedAudio{})
+		select {
+		case <-ch:
+			blocked = true
+		default:
+			return false, false
+		}
+	}
+	return blocked, c.startDecoding(path)
+}
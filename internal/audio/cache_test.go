@@ -0,0 +1,87 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio_test
+
+import (
+	"reflect"
+	"testing"
+
+	. "github.com/hajimehoshi/rpgsnack-runtime/internal/audio"
+)
+
+func TestPCMCacheBudget(t *testing.T) {
+	cases := []struct {
+		Paths  []string
+		Sizes  []int
+		Cached []string
+	}{
+		{
+			Paths:  []string{"a", "b"},
+			Sizes:  []int{4, 6},
+			Cached: []string{"a", "b"},
+		},
+		{
+			// The oldest audio is evicted.
+			Paths:  []string{"a", "b", "c"},
+			Sizes:  []int{4, 4, 4},
+			Cached: []string{"b", "c"},
+		},
+		{
+			Paths:  []string{"a", "b", "c"},
+			Sizes:  []int{2, 2, 8},
+			Cached: []string{"b", "c"},
+		},
+		{
+			// Audio larger than the budget is not cached.
+			Paths:  []string{"a", "b"},
+			Sizes:  []int{4, 11},
+			Cached: []string{"a"},
+		},
+	}
+	for _, c := range cases {
+		got := PCMCachedPathsForTesting(10, c.Paths, c.Sizes)
+		if !reflect.DeepEqual(got, c.Cached) {
+			t.Errorf("PCMCachedPathsForTesting(10, %v, %v): got: %v, want: %v", c.Paths, c.Sizes, got, c.Cached)
+		}
+	}
+}
+
+func TestPCMWait(t *testing.T) {
+	cases := []struct {
+		DecodingStarted bool
+		Blocked         bool
+	}{
+		{
+			// The audio in the queue is decoded by the caller instead.
+			DecodingStarted: false,
+			Blocked:         false,
+		},
+		{
+			// The caller waits for the worker.
+			DecodingStarted: true,
+			Blocked:         true,
+		},
+	}
+	for _, c := range cases {
+		blocked, workerDecodes := PCMWaitForTesting(c.DecodingStarted)
+		if blocked != c.Blocked {
+			t.Errorf("PCMWaitForTesting(%v): blocked: got: %v, want: %v", c.DecodingStarted, blocked, c.Blocked)
+		}
+		// The audio must not be decoded twice.
+		if workerDecodes {
+			t.Errorf("PCMWaitForTesting(%v): the worker must not decode the audio", c.DecodingStarted)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/interpolation"
+)
+
+// SetDucking sets whether BGM and BGSs are attenuated.
+//
+// volume is the rate of the attenuated volume [0-1].
+// attackFrames is the time to attenuate, and releaseFrames is the time to restore the volume.
+//
+// SetDucking is assumed to be called every frame.
+func SetDucking(ducking bool, volume float64, attackFrames, releaseFrames int) {
+	theAudio.SetDucking(ducking, volume, attackFrames, releaseFrames)
+}
+
+type ducking struct {
+	// requested is whether the ducking is requested from outside of this package.
+	requested bool
+
+	volume        float64
+	attackFrames  int
+	releaseFrames int
+
+	rate *interpolation.I
+}
+
+func (d *ducking) target(forced bool) float64 {
+	if d.requested || forced {
+		return d.volume
+	}
+	return 1
+}
+
+// update updates the volume rate.
+// forced is true when the ducking is needed regardless of the request, e.g., when a voice line is playing.
+func (d *ducking) update(forced bool) {
+	if d.rate == nil {
+		d.rate = interpolation.New(1)
+	}
+	if t := d.target(forced); t != d.rate.Dst() {
+		frames := d.releaseFrames
+		if t < d.rate.Current() {
+			frames = d.attackFrames
+		}
+		d.rate.Set(t, frames)
+	}
+	d.rate.Update()
+}
+
+func (d *ducking) current() float64 {
+	if d.rate == nil {
+		return 1
+	}
+	return d.rate.Current()
+}
+
+func (d *ducking) reset() {
+	d.requested = false
+	d.rate = nil
+}
+
+func (a *audio) SetDucking(ducking bool, volume float64, attackFrames, releaseFrames int) {
+	a.ducking.requested = ducking
+	a.ducking.volume = volume
+	a.ducking.attackFrames = attackFrames
+	a.ducking.releaseFrames = releaseFrames
+}
+
+// bgmVolumeRate returns the rate multiplied to BGM and BGS volumes.
+func (a *audio) bgmVolumeRate() float64 {
+	return volumeBias * bgmVolumeBias * a.ducking.current()
+}
+
+// DuckingRatesForTesting returns the volume rates after each frame where the ducking is requested or not.
+func DuckingRatesForTesting(requested []bool, volume float64, attackFrames, releaseFrames int) []float64 {
+	d := &ducking{
+		volume:        volume,
+		attackFrames:  attackFrames,
+		releaseFrames: releaseFrames,
+	}
+	rates := make([]float64, len(requested))
+	for i, r := range requested {
+		d.requested = r
+		d.update(false)
+		rates[i] = d.current()
+	}
+	return rates
+}
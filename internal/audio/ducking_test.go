@@ -0,0 +1,32 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio_test
+
+import (
+	"reflect"
+	"testing"
+
+	. "github.com/hajimehoshi/rpgsnack-runtime/internal/audio"
+)
+
+func TestDucking(t *testing.T) {
+	// The volume is attenuated in 2 frames, and restored in 4 frames.
+	requested := []bool{true, true, true, false, false, false, false, false}
+	got := DuckingRatesForTesting(requested, 0.5, 2, 4)
+	want := []float64{0.75, 0.5, 0.5, 0.625, 0.75, 0.875, 1, 1}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+}
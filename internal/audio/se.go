@@ -0,0 +1,261 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"math"
+
+	eaudio "github.com/hajimehoshi/ebiten/audio"
+)
+
+const (
+	// maxSEChannels is the maximum number of SEs played at the same time.
+	maxSEChannels = 16
+
+	// seCooldownFrames is the number of frames to suppress the same SE.
+	seCooldownFrames = 3
+
+	// 4 = 2 [channels] * 2 [bytes]
+	bytesPerFrame = 4
+)
+
+type SEOptions struct {
+	Volume float64
+
+	// Pitch is the rate of the playback speed. 1 is the original pitch.
+	Pitch float64
+
+	// Pan is the stereo position from -1 (left) to 1 (right).
+	Pan float64
+
+	// Priority is used to choose an SE to stop when too many SEs are playing.
+	// An SE with a higher priority can stop an SE with a lower or the same priority.
+	Priority int
+}
+
+func PlaySE(name string, volume float64) {
+	theAudio.PlaySE(name, &SEOptions{
+		Volume: volume,
+		Pitch:  1,
+	})
+}
+
+// PlaySEWithOptions plays the SE and returns its handle.
+// PlaySEWithOptions returns 0 when the SE is not played.
+func PlaySEWithOptions(name string, options *SEOptions) int {
+	return theAudio.PlaySE(name, options)
+}
+
+func StopSE(handle int) {
+	theAudio.StopSE(handle)
+}
+
+func StopSEByName(name string) {
+	theAudio.StopSEByName(name)
+}
+
+type seChannel struct {
+	handle   int
+	name     string
+	player   *eaudio.Player
+	priority int
+}
+
+func pcmSample(src []byte, frame int, channel int) float64 {
+	i := frame*bytesPerFrame + channel*2
+	return float64(int16(uint16(src[i]) | uint16(src[i+1])<<8))
+}
+
+// applyPitchAndPan returns new PCM bytes with the given pitch and pan.
+// The pitch is changed by resampling, so the length of the sound changes too.
+func applyPitchAndPan(src []byte, pitch, pan float64) []byte {
+	if pitch == 1 && pan == 0 {
+		return src
+	}
+
+	n := len(src) / bytesPerFrame
+	if n == 0 {
+		return src
+	}
+	m := int(float64(n) / pitch)
+
+	gains := [2]float64{1, 1}
+	if pan < 0 {
+		gains[1] = 1 + pan
+	} else {
+		gains[0] = 1 - pan
+	}
+
+	dst := make([]byte, m*bytesPerFrame)
+	for i := 0; i < m; i++ {
+		pos := float64(i) * pitch
+		j0 := int(pos)
+		j1 := j0 + 1
+		if j1 >= n {
+			j1 = n - 1
+		}
+		t := pos - float64(j0)
+		for ch := 0; ch < 2; ch++ {
+			v := (pcmSample(src, j0, ch)*(1-t) + pcmSample(src, j1, ch)*t) * gains[ch]
+			v = math.Max(math.MinInt16, math.Min(math.MaxInt16, v))
+			s := uint16(int16(v))
+			k := i*bytesPerFrame + ch*2
+			dst[k] = byte(s)
+			dst[k+1] = byte(s >> 8)
+		}
+	}
+	return dst
+}
+
+// seChannelIndexToStop returns the index of the channel to stop to play a new SE with the given priority.
+// seChannelIndexToStop returns -1 when there is a free channel, and returns false when the new SE cannot be played.
+func seChannelIndexToStop(channels []*seChannel, priority int) (int, bool) {
+	if len(channels) < maxSEChannels {
+		return -1, true
+	}
+	// Find the SE with the lowest priority. The oldest one is chosen among the same priority.
+	idx := 0
+	for i, c := range channels {
+		if c.priority < channels[idx].priority {
+			idx = i
+		}
+	}
+	if channels[idx].priority > priority {
+		return 0, false
+	}
+	return idx, true
+}
+
+func (a *audio) isSECoolingDown(name string) bool {
+	f, ok := a.seLastPlayedFrames[name]
+	return ok && a.frame-f < seCooldownFrames
+}
+
+func (a *audio) PlaySE(name string, options *SEOptions) int {
+	if a.err != nil {
+		return 0
+	}
+
+	if a.isSECoolingDown(name) {
+		return 0
+	}
+
+	idx, ok := seChannelIndexToStop(a.seChannels, options.Priority)
+	if !ok {
+		return 0
+	}
+	if idx >= 0 {
+		a.seChannels[idx].player.Close()
+		a.seChannels = append(a.seChannels[:idx], a.seChannels[idx+1:]...)
+	}
+
+	d, err := a.loadPCM("audio/se/" + name)
+	if err != nil {
+		a.err = err
+		return 0
+	}
+	bs := d.pcm
+
+	pitch := options.Pitch
+	if pitch == 0 {
+		pitch = 1
+	}
+	pitch = math.Max(0.25, math.Min(4, pitch))
+	pan := math.Max(-1, math.Min(1, options.Pan))
+	bs = applyPitchAndPan(bs, pitch, pan)
+
+	p, err := eaudio.NewPlayer(a.context, eaudio.BytesReadSeekCloser(bs))
+	if err != nil {
+		a.err = err
+		return 0
+	}
+	p.SetVolume(options.Volume * volumeBias * seVolumeBias)
+	p.Play()
+
+	a.nextSEHandle++
+	a.seChannels = append(a.seChannels, &seChannel{
+		handle:   a.nextSEHandle,
+		name:     name,
+		player:   p,
+		priority: options.Priority,
+	})
+	a.seLastPlayedFrames[name] = a.frame
+	return a.nextSEHandle
+}
+
+func (a *audio) StopSE(handle int) {
+	a.stopSEs(func(c *seChannel) bool {
+		return c.handle == handle
+	})
+}
+
+func (a *audio) StopSEByName(name string) {
+	a.stopSEs(func(c *seChannel) bool {
+		return c.name == name
+	})
+}
+
+func (a *audio) stopSEs(f func(c *seChannel) bool) {
+	chs := a.seChannels[:0]
+	for _, c := range a.seChannels {
+		if f(c) {
+			c.player.Close()
+			continue
+		}
+		chs = append(chs, c)
+	}
+	a.seChannels = chs
+}
+
+func (a *audio) updateSEs() {
+	a.frame++
+	a.stopSEs(func(c *seChannel) bool {
+		return !c.player.IsPlaying()
+	})
+	for name, f := range a.seLastPlayedFrames {
+		if a.frame-f >= seCooldownFrames {
+			delete(a.seLastPlayedFrames, name)
+		}
+	}
+}
+
+func SEChannelIndexToStopForTesting(priorities []int, priority int) (int, bool) {
+	chs := make([]*seChannel, len(priorities))
+	for i, p := range priorities {
+		chs[i] = &seChannel{
+			priority: p,
+		}
+	}
+	return seChannelIndexToStop(chs, priority)
+}
+
+// SECooldownForTesting tries to play the SE at each of the given ascending frames, and reports whether the SE is played.
+func SECooldownForTesting(name string, frames []int) []bool {
+	a := &audio{
+		seLastPlayedFrames: map[string]int{},
+	}
+	played := make([]bool, len(frames))
+	for i, f := range frames {
+		for a.frame < f {
+			a.updateSEs()
+		}
+		if a.isSECoolingDown(name) {
+			continue
+		}
+		a.seLastPlayedFrames[name] = a.frame
+		played[i] = true
+	}
+	return played
+}
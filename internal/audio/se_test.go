@@ -0,0 +1,89 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio_test
+
+import (
+	"reflect"
+	"testing"
+
+	. "github.com/hajimehoshi/rpgsnack-runtime/internal/audio"
+)
+
+// priorities returns the priorities of 16 channels, which is the maximum number of channels.
+func priorities(p int, others map[int]int) []int {
+	ps := make([]int, 16)
+	for i := range ps {
+		ps[i] = p
+		if o, ok := others[i]; ok {
+			ps[i] = o
+		}
+	}
+	return ps
+}
+
+func TestSEChannelIndexToStop(t *testing.T) {
+	cases := []struct {
+		Priorities []int
+		Priority   int
+		Index      int
+		OK         bool
+	}{
+		{
+			Priorities: priorities(0, nil)[:15],
+			Priority:   0,
+			Index:      -1,
+			OK:         true,
+		},
+		{
+			Priorities: priorities(0, nil),
+			Priority:   0,
+			Index:      0,
+			OK:         true,
+		},
+		{
+			Priorities: priorities(1, map[int]int{2: 0, 7: 0}),
+			Priority:   0,
+			Index:      2,
+			OK:         true,
+		},
+		{
+			Priorities: priorities(2, nil),
+			Priority:   1,
+			Index:      0,
+			OK:         false,
+		},
+		{
+			Priorities: priorities(2, map[int]int{5: 1, 9: 1}),
+			Priority:   3,
+			Index:      5,
+			OK:         true,
+		},
+	}
+	for _, c := range cases {
+		idx, ok := SEChannelIndexToStopForTesting(c.Priorities, c.Priority)
+		if ok != c.OK || (ok && idx != c.Index) {
+			t.Errorf("SEChannelIndexToStopForTesting(%v, %d): got: %d, %v, want: %d, %v", c.Priorities, c.Priority, idx, ok, c.Index, c.OK)
+		}
+	}
+}
+
+func TestSECooldown(t *testing.T) {
+	frames := []int{0, 1, 2, 3, 4, 6, 7}
+	got := SECooldownForTesting("se", frames)
+	want := []bool{true, false, false, true, false, true, false}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"golang.org/x/text/language"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/assets"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/lang"
+)
+
+// PlayVoice plays the voice line. The currently playing voice line is stopped.
+//
+// The voice is looked up in the same way as localized images, e.g.,
+// audio/voice/name@ja.ogg is used in Japanese, and audio/voice/name.ogg is used as a fallback.
+func PlayVoice(name string, volume float64) {
+	theAudio.PlayVoice(name, volume)
+}
+
+// StopVoice stops the voice line if the voice line of the given name is playing.
+func StopVoice(name string) {
+	theAudio.StopVoice(name)
+}
+
+// IsVoicePlaying reports whether the voice line of the given name is playing.
+func IsVoicePlaying(name string) bool {
+	return theAudio.IsVoicePlaying(name)
+}
+
+func audioExists(path string) bool {
+	for _, ext := range []string{".mp3", ".ogg", ".wav"} {
+		if assets.Exists(path + ext) {
+			return true
+		}
+	}
+	return false
+}
+
+func localizedVoicePath(name string, l language.Tag, exists func(path string) bool) string {
+	l = lang.Normalize(l)
+	// Look for the exact localized voice (ex: zh-Hant)
+	if p := "audio/voice/" + name + "@" + l.String(); exists(p) {
+		return p
+	}
+	// If not fallback to the base (ex: zh)
+	t, _ := l.Base()
+	if p := "audio/voice/" + name + "@" + t.String(); exists(p) {
+		return p
+	}
+	return "audio/voice/" + name
+}
+
+func LocalizedVoicePathForTesting(name string, l language.Tag, paths []string) string {
+	return localizedVoicePath(name, l, func(path string) bool {
+		for _, p := range paths {
+			if p == path {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func (a *audio) PlayVoice(name string, volume float64) {
+	if a.err != nil {
+		return
+	}
+	a.stopVoice()
+
+	p, err := a.getPlayer(localizedVoicePath(name, lang.Get(), audioExists), false)
+	if err != nil {
+		a.err = err
+		return
+	}
+	p.SetVolume(volume * volumeBias * seVolumeBias)
+	p.Play()
+	a.voicePlayer = p
+	a.voiceName = name
+}
+
+func (a *audio) StopVoice(name string) {
+	if a.voiceName != name {
+		return
+	}
+	a.stopVoice()
+}
+
+func (a *audio) stopVoice() {
+	if a.voicePlayer == nil {
+		return
+	}
+	a.voicePlayer.Close()
+	a.voicePlayer = nil
+	a.voiceName = ""
+}
+
+func (a *audio) IsVoicePlaying(name string) bool {
+	if a.voiceName != name {
+		return false
+	}
+	return a.isVoicePlaying()
+}
+
+func (a *audio) isVoicePlaying() bool {
+	return a.voicePlayer != nil && a.voicePlayer.IsPlaying()
+}
+
+func (a *audio) updateVoice() {
+	if a.voicePlayer == nil {
+		return
+	}
+	if !a.voicePlayer.IsPlaying() {
+		a.stopVoice()
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio_test
+
+import (
+	"testing"
+
+	"golang.org/x/text/language"
+
+	. "github.com/hajimehoshi/rpgsnack-runtime/internal/audio"
+)
+
+func TestLocalizedVoicePath(t *testing.T) {
+	paths := []string{
+		"audio/voice/hello@ja",
+		"audio/voice/hello@zh-Hant",
+		"audio/voice/hello@zh",
+		"audio/voice/bye@zh",
+	}
+	cases := []struct {
+		Name string
+		Lang language.Tag
+		Out  string
+	}{
+		{
+			Name: "hello",
+			Lang: language.Japanese,
+			Out:  "audio/voice/hello@ja",
+		},
+		{
+			Name: "hello",
+			Lang: language.English,
+			Out:  "audio/voice/hello",
+		},
+		{
+			Name: "hello",
+			Lang: language.TraditionalChinese,
+			Out:  "audio/voice/hello@zh-Hant",
+		},
+		{
+			// zh is normalized to zh-Hans, which falls back to zh.
+			Name: "hello",
+			Lang: language.Chinese,
+			Out:  "audio/voice/hello@zh",
+		},
+		{
+			Name: "bye",
+			Lang: language.TraditionalChinese,
+			Out:  "audio/voice/bye@zh",
+		},
+		{
+			Name: "bye",
+			Lang: language.Japanese,
+			Out:  "audio/voice/bye",
+		},
+	}
+	for _, c := range cases {
+		got := LocalizedVoicePathForTesting(c.Name, c.Lang, paths)
+		if got != c.Out {
+			t.Errorf("LocalizedVoicePathForTesting(%q, %s): got: %s, want: %s", c.Name, c.Lang, got, c.Out)
+		}
+	}
+}
@@ -512,6 +512,10 @@ func (c *Character) SetSpeed(speed data.Speed) {
 	}
 }
 
+func (c *Character) Visible() bool {
+	return c.visible
+}
+
 func (c *Character) SetVisibility(visible bool) {
 	c.visible = visible
 }
@@ -558,6 +562,10 @@ func (c *Character) SetDir(dir data.Dir) {
 	c.dir = dir
 }
 
+func (c *Character) Opacity() int {
+	return c.opacity
+}
+
 func (c *Character) ChangeOpacity(opacity int, count int) {
 	c.opacityCount = count
 	c.opacityMaxCount = count
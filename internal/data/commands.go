@@ -17,6 +17,7 @@ package data
 import (
 	"fmt"
 	"runtime"
+	"unicode"
 
 	"github.com/vmihailenco/msgpack"
 
@@ -175,6 +176,9 @@ func (c *Command) DecodeMsgpack(dec *msgpack.Decoder) error {
 		if err := msgpack.Unmarshal(argsBin, a); err != nil {
 			return err
 		}
+		if a.Speaker != nil && a.Speaker.FacePosition == "" {
+			a.Speaker.FacePosition = FacePositionLeft
+		}
 		c.Args = a
 	case CommandNameShowMessage:
 		a := &CommandArgsShowMessage{}
@@ -184,14 +188,46 @@ func (c *Command) DecodeMsgpack(dec *msgpack.Decoder) error {
 		if a.TextAlign == "" {
 			a.TextAlign = TextAlignLeft
 		}
+		if a.Speaker != nil && a.Speaker.FacePosition == "" {
+			a.Speaker.FacePosition = FacePositionLeft
+		}
 		c.Args = a
 	case CommandNameShowHint:
 	case CommandNameShowChoices:
-		a := &CommandArgsShowChoices{}
+		a := &CommandArgsShowChoices{
+			DefaultIndex: -1,
+		}
 		if err := msgpack.Unmarshal(argsBin, a); err != nil {
 			return err
 		}
 		c.Args = a
+	case CommandNameInputText:
+		a := &CommandArgsInputText{}
+		if err := msgpack.Unmarshal(argsBin, a); err != nil {
+			return err
+		}
+		if a.Layout == "" {
+			a.Layout = InputTextLayoutLatin
+		}
+		if a.DestType == "" {
+			a.DestType = InputTextDestTypeVariable
+		}
+		c.Args = a
+	case CommandNameInputNumber:
+		a := &CommandArgsInputNumber{}
+		if err := msgpack.Unmarshal(argsBin, a); err != nil {
+			return err
+		}
+		if a.Style == "" {
+			a.Style = InputNumberStyleDial
+		}
+		if a.Digits == 0 {
+			a.Digits = 4
+		}
+		if a.InitialType == "" {
+			a.InitialType = ValueTypeVariable
+		}
+		c.Args = a
 	case CommandNameSetSwitch:
 		a := &CommandArgsSetSwitch{}
 		if err := msgpack.Unmarshal(argsBin, a); err != nil {
@@ -246,11 +282,39 @@ func (c *Command) DecodeMsgpack(dec *msgpack.Decoder) error {
 			return err
 		}
 		c.Args = a
+	case CommandNameSetDarkness:
+		a := &CommandArgsSetDarkness{}
+		if err := msgpack.Unmarshal(argsBin, a); err != nil {
+			return err
+		}
+		c.Args = a
+	case CommandNameSetLight:
+		a := &CommandArgsSetLight{}
+		if err := msgpack.Unmarshal(argsBin, a); err != nil {
+			return err
+		}
+		c.Args = a
+	case CommandNameEraseLight:
+		a := &CommandArgsEraseLight{}
+		if err := msgpack.Unmarshal(argsBin, a); err != nil {
+			return err
+		}
+		c.Args = a
 	case CommandNamePlaySE:
 		a := &CommandArgsPlaySE{}
 		if err := msgpack.Unmarshal(argsBin, a); err != nil {
 			return err
 		}
+		// TODO Implement Decoder
+		if a.Pitch == 0 {
+			a.Pitch = 100
+		}
+		c.Args = a
+	case CommandNameStopSE:
+		a := &CommandArgsStopSE{}
+		if err := msgpack.Unmarshal(argsBin, a); err != nil {
+			return err
+		}
 		c.Args = a
 	case CommandNamePlayBGM:
 		a := &CommandArgsPlayBGM{}
@@ -264,6 +328,30 @@ func (c *Command) DecodeMsgpack(dec *msgpack.Decoder) error {
 			return err
 		}
 		c.Args = a
+	case CommandNamePlayBGS:
+		a := &CommandArgsPlayBGS{}
+		if err := msgpack.Unmarshal(argsBin, a); err != nil {
+			return err
+		}
+		c.Args = a
+	case CommandNameStopBGS:
+		a := &CommandArgsStopBGS{}
+		if err := msgpack.Unmarshal(argsBin, a); err != nil {
+			return err
+		}
+		c.Args = a
+	case CommandNameFadeBGS:
+		a := &CommandArgsFadeBGS{}
+		if err := msgpack.Unmarshal(argsBin, a); err != nil {
+			return err
+		}
+		c.Args = a
+	case CommandNamePreloadAudio:
+		a := &CommandArgsPreloadAudio{}
+		if err := msgpack.Unmarshal(argsBin, a); err != nil {
+			return err
+		}
+		c.Args = a
 	case CommandNameSave:
 	case CommandNameRequestReview:
 	case CommandNameUnlockAchievement:
@@ -291,7 +379,9 @@ func (c *Command) DecodeMsgpack(dec *msgpack.Decoder) error {
 		}
 		c.Args = a
 	case CommandNameWeather:
-		a := &CommandArgsWeather{}
+		a := &CommandArgsWeather{
+			Intensity: 100,
+		}
 		if err := msgpack.Unmarshal(argsBin, a); err != nil {
 			return err
 		}
@@ -434,6 +524,19 @@ func (c *Command) DecodeMsgpack(dec *msgpack.Decoder) error {
 			a.Priority = PicturePriorityOverlay
 		}
 		c.Args = a
+	case CommandNameShowTextPicture:
+		a := &CommandArgsShowTextPicture{}
+		if err := msgpack.Unmarshal(argsBin, a); err != nil {
+			return err
+		}
+		// TODO Implement Decoder
+		if a.Priority == "" {
+			a.Priority = PicturePriorityOverlay
+		}
+		if a.TextAlign == "" {
+			a.TextAlign = TextAlignLeft
+		}
+		c.Args = a
 	case CommandNameErasePicture:
 		a := &CommandArgsErasePicture{}
 		if err := msgpack.Unmarshal(argsBin, a); err != nil {
@@ -476,6 +579,12 @@ func (c *Command) DecodeMsgpack(dec *msgpack.Decoder) error {
 			return err
 		}
 		c.Args = a
+	case CommandNameAttachPicture:
+		a := &CommandArgsAttachPicture{}
+		if err := msgpack.Unmarshal(argsBin, a); err != nil {
+			return err
+		}
+		c.Args = a
 	case CommandNameChangeBackground:
 		a := &CommandArgsChangeBackground{}
 		if err := msgpack.Unmarshal(argsBin, a); err != nil {
@@ -526,6 +635,8 @@ const (
 	CommandNameShowMessage       CommandName = "show_message"
 	CommandNameShowHint          CommandName = "show_hint"
 	CommandNameShowChoices       CommandName = "show_choices"
+	CommandNameInputText         CommandName = "input_text"
+	CommandNameInputNumber       CommandName = "input_number"
 	CommandNameSetSwitch         CommandName = "set_switch"
 	CommandNameSetSelfSwitch     CommandName = "set_self_switch"
 	CommandNameSetVariable       CommandName = "set_variable"
@@ -535,9 +646,17 @@ const (
 	CommandNameSetRoute          CommandName = "set_route"
 	CommandNameTintScreen        CommandName = "tint_screen"
 	CommandNameShake             CommandName = "shake"
+	CommandNameSetDarkness       CommandName = "set_darkness"
+	CommandNameSetLight          CommandName = "set_light"
+	CommandNameEraseLight        CommandName = "erase_light"
 	CommandNamePlaySE            CommandName = "play_se"
+	CommandNameStopSE            CommandName = "stop_se"
 	CommandNamePlayBGM           CommandName = "play_bgm"
 	CommandNameStopBGM           CommandName = "stop_bgm"
+	CommandNamePlayBGS           CommandName = "play_bgs"
+	CommandNameStopBGS           CommandName = "stop_bgs"
+	CommandNameFadeBGS           CommandName = "fade_bgs"
+	CommandNamePreloadAudio      CommandName = "preload_audio"
 	CommandNameSave              CommandName = "save"
 	CommandNameGotoTitle         CommandName = "goto_title"
 	CommandNameAutoSave          CommandName = "autosave"
@@ -567,6 +686,7 @@ const (
 	CommandNameHideInventory CommandName = "hide_inventory"
 
 	CommandNameShowPicture        CommandName = "show_picture"
+	CommandNameShowTextPicture    CommandName = "show_text_picture"
 	CommandNameErasePicture       CommandName = "erase_picture"
 	CommandNameMovePicture        CommandName = "move_picture"
 	CommandNameScalePicture       CommandName = "scale_picture"
@@ -574,6 +694,7 @@ const (
 	CommandNameFadePicture        CommandName = "fade_picture"
 	CommandNameTintPicture        CommandName = "tint_picture"
 	CommandNameChangePictureImage CommandName = "change_picture_image"
+	CommandNameAttachPicture      CommandName = "attach_picture"
 	CommandNameChangeBackground   CommandName = "change_background"
 	CommandNameChangeForeground   CommandName = "change_foreground"
 
@@ -630,10 +751,13 @@ type CommandArgsWait struct {
 }
 
 type CommandArgsShowBalloon struct {
-	EventID        int         `msgpack:"eventId"`
-	ContentID      UUID        `msgpack:"content"`
-	BalloonType    BalloonType `msgpack:"balloonType"`
-	MessageStyleID int         `msgpack:"messageStyleId"`
+	EventID        int             `msgpack:"eventId"`
+	ContentID      UUID            `msgpack:"content"`
+	BalloonType    BalloonType     `msgpack:"balloonType"`
+	MessageStyleID int             `msgpack:"messageStyleId"`
+	Voice          string          `msgpack:"voice"`
+	WaitVoice      bool            `msgpack:"waitVoice"`
+	Speaker        *MessageSpeaker `msgpack:"speaker"`
 }
 
 type CommandArgsShowMessage struct {
@@ -643,16 +767,147 @@ type CommandArgsShowMessage struct {
 	PositionType   MessagePositionType `msgpack:"positionType"`
 	TextAlign      TextAlign           `msgpack:"textAlign"`
 	MessageStyleID int                 `msgpack:"messageStyleId"`
+	Voice          string              `msgpack:"voice"`
+	WaitVoice      bool                `msgpack:"waitVoice"`
+	Speaker        *MessageSpeaker     `msgpack:"speaker"`
+}
+
+// MessageSpeaker is a speaker's name and face shown with a message.
+type MessageSpeaker struct {
+	// NameID is the text ID of the name. If NameID is zero, Name is used as it is.
+	NameID UUID   `msgpack:"nameId"`
+	Name   string `msgpack:"name"`
+
+	// Face is a picture name of the face or the bust.
+	// The picture is a horizontal strip of frames whose width is the picture height.
+	// A picture narrower than its height, like a bust, has only one frame.
+	Face         string       `msgpack:"face"`
+	FaceFrame    int          `msgpack:"faceFrame"`
+	FacePosition FacePosition `msgpack:"facePosition"`
 }
 
+// ChoiceCondition is the conditions and the appearance of a choice.
 type ChoiceCondition struct {
 	Visible *Condition `msgpack:"visible"`
 	Checked *Condition `msgpack:"checked"`
+
+	// Enabled is the condition to choose the choice. A disabled choice is shown but can't be chosen.
+	Enabled *Condition `msgpack:"enabled"`
+
+	// DisabledReason is the text shown with the choice when the choice is disabled.
+	DisabledReason UUID `msgpack:"disabledReason"`
+
+	// Icon is the name of the icon shown before the choice text.
+	Icon string `msgpack:"icon"`
 }
 
+// CommandArgsShowChoices is the arguments of the show_choices command.
+//
+// When Cancelable is true, the branch next to the choices' branches is executed by the back button.
 type CommandArgsShowChoices struct {
 	ChoiceIDs  []UUID             `msgpack:"choices"`
 	Conditions []*ChoiceCondition `msgpack:"conditions"`
+
+	// DefaultIndex is the index of the highlighted choice. -1 means no choice is highlighted.
+	DefaultIndex int `msgpack:"defaultIndex"`
+
+	// Timeout is the time limit in the same unit as the wait command. 0 means no time limit.
+	Timeout int `msgpack:"timeout"`
+
+	// TimeoutIndex is the index of the choice chosen when the time is up.
+	TimeoutIndex int `msgpack:"timeoutIndex"`
+
+	Cancelable bool `msgpack:"cancelable"`
+}
+
+type InputTextLayout string
+
+const (
+	InputTextLayoutLatin  InputTextLayout = "latin"
+	InputTextLayoutKana   InputTextLayout = "kana"
+	InputTextLayoutNumber InputTextLayout = "number"
+)
+
+type InputTextFilter string
+
+const (
+	InputTextFilterNone         InputTextFilter = ""
+	InputTextFilterAlphabet     InputTextFilter = "alphabet"
+	InputTextFilterAlphanumeric InputTextFilter = "alphanumeric"
+	InputTextFilterNumber       InputTextFilter = "number"
+	InputTextFilterKana         InputTextFilter = "kana"
+)
+
+// Accepts reports whether the rune r can be entered with the filter.
+func (f InputTextFilter) Accepts(r rune) bool {
+	switch f {
+	case InputTextFilterNone:
+		// A backslash is not allowed since it would start a message command.
+		return unicode.IsPrint(r) && r != '\\'
+	case InputTextFilterAlphabet:
+		return ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z')
+	case InputTextFilterAlphanumeric:
+		return ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9')
+	case InputTextFilterNumber:
+		return '0' <= r && r <= '9'
+	case InputTextFilterKana:
+		return unicode.In(r, unicode.Hiragana, unicode.Katakana) || r == 'ー'
+	}
+	return false
+}
+
+type InputTextDestType string
+
+const (
+	InputTextDestTypeVariable InputTextDestType = "variable"
+	InputTextDestTypeTable    InputTextDestType = "table"
+)
+
+// CommandArgsInputText is the arguments of the input_text command.
+//
+// The first branch is executed when the text is entered, and the second branch is executed when the input is canceled.
+type CommandArgsInputText struct {
+	Title      UUID              `msgpack:"title"`
+	Layout     InputTextLayout   `msgpack:"layout"`
+	MaxLength  int               `msgpack:"maxLength"`
+	Filter     InputTextFilter   `msgpack:"filter"`
+	Cancelable bool              `msgpack:"cancelable"`
+	DestType   InputTextDestType `msgpack:"destType"`
+
+	// ID is the string variable ID when DestType is InputTextDestTypeVariable.
+	ID int `msgpack:"id"`
+
+	// Table is the table value when DestType is InputTextDestTypeTable.
+	Table *TableValueArgs `msgpack:"table"`
+}
+
+type InputNumberStyle string
+
+const (
+	InputNumberStyleDial   InputNumberStyle = "dial"
+	InputNumberStyleKeypad InputNumberStyle = "keypad"
+)
+
+// InputNumberMaxDigits is the maximum number of digits for the input_number command.
+const InputNumberMaxDigits = 9
+
+// CommandArgsInputNumber is the arguments of the input_number command.
+//
+// The first branch is executed when the number is entered, and the second branch is executed when the input is canceled.
+type CommandArgsInputNumber struct {
+	Title      UUID             `msgpack:"title"`
+	Style      InputNumberStyle `msgpack:"style"`
+	Digits     int              `msgpack:"digits"`
+	Min        int              `msgpack:"min"`
+	Max        int              `msgpack:"max"`
+	Cancelable bool             `msgpack:"cancelable"`
+
+	// ID is the variable ID to store the number.
+	ID int `msgpack:"id"`
+
+	// Initial is the initial value, or the variable ID of the initial value when InitialType is ValueTypeVariable.
+	InitialType ValueType `msgpack:"initialType"`
+	Initial     int       `msgpack:"initial"`
 }
 
 type CommandArgsSetSwitch struct {
@@ -875,9 +1130,68 @@ type CommandArgsTintScreen struct {
 	Wait  bool `msgpack:"wait"`
 }
 
+type CommandArgsSetDarkness struct {
+	Darkness int  `msgpack:"darkness"`
+	Red      int  `msgpack:"red"`
+	Green    int  `msgpack:"green"`
+	Blue     int  `msgpack:"blue"`
+	Time     int  `msgpack:"time"`
+	Wait     bool `msgpack:"wait"`
+}
+
+type LightTargetType string
+
+const (
+	LightTargetTypeCharacter LightTargetType = "character"
+	LightTargetTypeTile      LightTargetType = "tile"
+)
+
+type CommandArgsSetLight struct {
+	ID           int             `msgpack:"id"`
+	IDValueType  ValueType       `msgpack:"idValueType"`
+	TargetType   LightTargetType `msgpack:"targetType"`
+	EventID      int             `msgpack:"eventId"`
+	X            int             `msgpack:"x"`
+	Y            int             `msgpack:"y"`
+	PosValueType ValueType       `msgpack:"posValueType"`
+	Radius       int             `msgpack:"radius"`
+	Red          int             `msgpack:"red"`
+	Green        int             `msgpack:"green"`
+	Blue         int             `msgpack:"blue"`
+	Flicker      int             `msgpack:"flicker"`
+	Time         int             `msgpack:"time"`
+}
+
+type CommandArgsEraseLight struct {
+	ID          int       `msgpack:"id"`
+	IDValueType ValueType `msgpack:"idValueType"`
+}
+
 type CommandArgsPlaySE struct {
-	Name   string `msgpack:"name"`
-	Volume int    `msgpack:"volume"`
+	Name             string `msgpack:"name"`
+	Volume           int    `msgpack:"volume"`
+	Pitch            int    `msgpack:"pitch"`
+	Pan              int    `msgpack:"pan"`
+	Priority         int    `msgpack:"priority"`
+	HandleVariableID int    `msgpack:"handleVariableId"`
+}
+
+type AudioType string
+
+const (
+	AudioTypeBGM AudioType = "bgm"
+	AudioTypeBGS AudioType = "bgs"
+	AudioTypeSE  AudioType = "se"
+)
+
+type CommandArgsPreloadAudio struct {
+	Type  AudioType `msgpack:"type"`
+	Names []string  `msgpack:"names"`
+}
+
+type CommandArgsStopSE struct {
+	Name             string `msgpack:"name"`
+	HandleVariableID int    `msgpack:"handleVariableId"`
 }
 
 type CommandArgsPlayBGM struct {
@@ -885,6 +1199,7 @@ type CommandArgsPlayBGM struct {
 	NameValueType FileValueType
 	Volume        int
 	FadeTime      int
+	Resume        bool
 }
 
 func (c *CommandArgsPlayBGM) EncodeMsgpack(enc *msgpack.Encoder) error {
@@ -900,11 +1215,14 @@ func (c *CommandArgsPlayBGM) EncodeMsgpack(enc *msgpack.Encoder) error {
 	e.EncodeString(string(c.NameValueType))
 
 	e.EncodeString("volume")
-	e.EncodeInt(c.FadeTime)
+	e.EncodeInt(c.Volume)
 
 	e.EncodeString("fadeTime")
 	e.EncodeInt(c.FadeTime)
 
+	e.EncodeString("resume")
+	e.EncodeBool(c.Resume)
+
 	e.EncodeString("name")
 	e.EncodeAny(c.Name)
 
@@ -931,6 +1249,8 @@ func (c *CommandArgsPlayBGM) DecodeMsgpack(dec *msgpack.Decoder) error {
 			c.Volume = d.DecodeInt()
 		case "fadeTime":
 			c.FadeTime = d.DecodeInt()
+		case "resume":
+			c.Resume = d.DecodeBool()
 		default:
 			if err := d.Error(); err != nil {
 				return fmt.Errorf("data: CommandArgsPlayBGM.DecodeMsgpack failed: %v", err)
@@ -969,6 +1289,25 @@ type CommandArgsStopBGM struct {
 	FadeTime int `msgpack:"fadeTime"`
 }
 
+type CommandArgsPlayBGS struct {
+	Channel  int    `msgpack:"channel"`
+	Name     string `msgpack:"name"`
+	Volume   int    `msgpack:"volume"`
+	FadeTime int    `msgpack:"fadeTime"`
+}
+
+type CommandArgsStopBGS struct {
+	Channel  int `msgpack:"channel"`
+	FadeTime int `msgpack:"fadeTime"`
+}
+
+type CommandArgsFadeBGS struct {
+	Channel int  `msgpack:"channel"`
+	Volume  int  `msgpack:"volume"`
+	Time    int  `msgpack:"time"`
+	Wait    bool `msgpack:"wait"`
+}
+
 type CommandArgsUnlockAchievement struct {
 	ID int `msgpack:"id"`
 }
@@ -1031,15 +1370,25 @@ type CommandArgsPlayerSpeed struct {
 }
 
 type CommandArgsWeather struct {
-	Type WeatherType `msgpack:"type"`
+	Type          WeatherType `msgpack:"type"`
+	Intensity     int         `msgpack:"intensity"`     // in percent
+	WindDirection int         `msgpack:"windDirection"` // in degrees. 0 is right and 90 is down.
+	WindStrength  int         `msgpack:"windStrength"`  // in percent. 100 is one pixel per frame.
+	Image         string      `msgpack:"image"`         // optional particle image in images/weather
+	Time          int         `msgpack:"time"`
 }
 
 type WeatherType string
 
 const (
-	WeatherTypeNone WeatherType = "none"
-	WeatherTypeSnow WeatherType = "snow"
-	WeatherTypeRain WeatherType = "rain"
+	WeatherTypeNone      WeatherType = "none"
+	WeatherTypeSnow      WeatherType = "snow"
+	WeatherTypeRain      WeatherType = "rain"
+	WeatherTypeFog       WeatherType = "fog"
+	WeatherTypeLeaves    WeatherType = "leaves"
+	WeatherTypePetals    WeatherType = "petals"
+	WeatherTypeFireflies WeatherType = "fireflies"
+	WeatherTypeSandstorm WeatherType = "sandstorm"
 )
 
 type CommandArgsMoveCharacter struct {
@@ -1395,6 +1744,33 @@ type CommandArgsShowPicture struct {
 	Priority     PicturePriorityType  `msgpack:"priority"`
 	BlendType    ShowPictureBlendType `msgpack:"blendType"`
 	Touchable    bool                 `msgpack:"touchable"`
+
+	// TouchAlphaThreshold is the minimum alpha value (0-255) of the touchable pixels.
+	// 0 means the whole bounding box is touchable.
+	TouchAlphaThreshold int `msgpack:"touchAlphaThreshold"`
+}
+
+type CommandArgsShowTextPicture struct {
+	ID           int                  `msgpack:"id"`
+	IDValueType  ValueType            `msgpack:"idValueType"`
+	ContentID    UUID                 `msgpack:"content"`
+	Text         string               `msgpack:"text"`
+	FontScale    int                  `msgpack:"fontScale"`
+	Color        string               `msgpack:"color"`
+	TextAlign    TextAlign            `msgpack:"textAlign"`
+	WrapWidth    int                  `msgpack:"wrapWidth"`
+	OriginX      float64              `msgpack:"originX"`
+	OriginY      float64              `msgpack:"originY"`
+	X            int                  `msgpack:"x"`
+	Y            int                  `msgpack:"y"`
+	PosValueType ValueType            `msgpack:"posValueType"`
+	ScaleX       int                  `msgpack:"scaleX"`
+	ScaleY       int                  `msgpack:"scaleY"`
+	Angle        int                  `msgpack:"angle"`
+	Opacity      int                  `msgpack:"opacity"`
+	Priority     PicturePriorityType  `msgpack:"priority"`
+	BlendType    ShowPictureBlendType `msgpack:"blendType"`
+	Touchable    bool                 `msgpack:"touchable"`
 }
 
 type CommandArgsErasePicture struct {
@@ -1452,6 +1828,14 @@ type CommandArgsTintPicture struct {
 	Wait        bool      `msgpack:"wait"`
 }
 
+type CommandArgsAttachPicture struct {
+	ID                int               `msgpack:"id"`
+	IDValueType       ValueType         `msgpack:"idValueType"`
+	ParentType        PictureParentType `msgpack:"parentType"`
+	ParentID          int               `msgpack:"parentId"`
+	ParentIDValueType ValueType         `msgpack:"parentIdValueType"`
+}
+
 type CommandArgsChangePictureImage struct {
 	ID             int
 	IDValueType    ValueType
@@ -1861,6 +2245,13 @@ const (
 	MessagePositionAuto   MessagePositionType = "auto"
 )
 
+type FacePosition string
+
+const (
+	FacePositionLeft  FacePosition = "left"
+	FacePositionRight FacePosition = "right"
+)
+
 type MessageBackground string
 
 const (
@@ -1877,6 +2268,14 @@ const (
 	PicturePriorityOverlay PicturePriorityType = "overlay"
 )
 
+type PictureParentType string
+
+const (
+	PictureParentTypeNone      PictureParentType = "none"
+	PictureParentTypePicture   PictureParentType = "picture"
+	PictureParentTypeCharacter PictureParentType = "character"
+)
+
 type ShakeDirection string
 
 const (
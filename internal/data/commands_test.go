@@ -134,3 +134,214 @@ func TestSetCharacterProperty(t *testing.T) {
 		}
 	}
 }
+
+func TestShowMessageSpeaker(t *testing.T) {
+	c := &Command{
+		Name: CommandNameShowMessage,
+	}
+	tests := []struct {
+		speaker *MessageSpeaker
+		want    *MessageSpeaker
+	}{
+		{
+			speaker: nil,
+			want:    nil,
+		},
+		{
+			speaker: &MessageSpeaker{
+				Name: "Alice",
+				Face: "alice",
+			},
+			want: &MessageSpeaker{
+				Name:         "Alice",
+				Face:         "alice",
+				FacePosition: FacePositionLeft,
+			},
+		},
+		{
+			speaker: &MessageSpeaker{
+				Face:         "bob",
+				FaceFrame:    2,
+				FacePosition: FacePositionRight,
+			},
+			want: &MessageSpeaker{
+				Face:         "bob",
+				FaceFrame:    2,
+				FacePosition: FacePositionRight,
+			},
+		},
+	}
+	for _, test := range tests {
+		c.Args = &CommandArgsShowMessage{
+			Speaker: test.speaker,
+		}
+		b, err := msgpack.Marshal(c)
+		if err != nil {
+			t.Error(err)
+		}
+		var c2 *Command
+		if err := msgpack.Unmarshal(b, &c2); err != nil {
+			t.Error(err)
+		}
+		got := c2.Args.(*CommandArgsShowMessage).Speaker
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("got: %v, want: %v", got, test.want)
+		}
+	}
+}
+
+func TestInputNumberDefaults(t *testing.T) {
+	c := &Command{
+		Name: CommandNameInputNumber,
+		Args: &CommandArgsInputNumber{
+			ID: 3,
+		},
+	}
+	b, err := msgpack.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c2 *Command
+	if err := msgpack.Unmarshal(b, &c2); err != nil {
+		t.Fatal(err)
+	}
+	got := c2.Args.(*CommandArgsInputNumber)
+	want := &CommandArgsInputNumber{
+		Style:       InputNumberStyleDial,
+		Digits:      4,
+		ID:          3,
+		InitialType: ValueTypeVariable,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+}
+
+func TestShowChoicesDefaultIndex(t *testing.T) {
+	// Data without the default index doesn't highlight any choices.
+	b, err := msgpack.Marshal(map[string]interface{}{
+		"name": CommandNameShowChoices,
+		"args": map[string]interface{}{
+			"choices": []UUID{},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c *Command
+	if err := msgpack.Unmarshal(b, &c); err != nil {
+		t.Fatal(err)
+	}
+	got := c.Args.(*CommandArgsShowChoices).DefaultIndex
+	want := -1
+	if got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+}
+
+func TestPlayBGM(t *testing.T) {
+	c := &Command{
+		Name: CommandNamePlayBGM,
+		Args: &CommandArgsPlayBGM{
+			Name:          "bgm",
+			NameValueType: FileValueTypeConstant,
+			Volume:        80,
+			FadeTime:      3,
+		},
+	}
+	b, err := msgpack.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c2 *Command
+	if err := msgpack.Unmarshal(b, &c2); err != nil {
+		t.Fatal(err)
+	}
+	got := c2.Args.(*CommandArgsPlayBGM)
+	if got.Volume != 80 || got.FadeTime != 3 {
+		t.Errorf("got: volume: %d, fade time: %d, want: volume: 80, fade time: 3", got.Volume, got.FadeTime)
+	}
+}
+
+func TestWeatherIntensity(t *testing.T) {
+	cases := []struct {
+		Args map[string]interface{}
+		Want int
+	}{
+		{
+			Args: map[string]interface{}{"type": WeatherTypeRain},
+			Want: 100,
+		},
+		{
+			Args: map[string]interface{}{"type": WeatherTypeRain, "intensity": 0},
+			Want: 0,
+		},
+		{
+			Args: map[string]interface{}{"type": WeatherTypeRain, "intensity": 50},
+			Want: 50,
+		},
+	}
+	for _, c := range cases {
+		b, err := msgpack.Marshal(map[string]interface{}{
+			"name": CommandNameWeather,
+			"args": c.Args,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var cmd *Command
+		if err := msgpack.Unmarshal(b, &cmd); err != nil {
+			t.Fatal(err)
+		}
+		got := cmd.Args.(*CommandArgsWeather).Intensity
+		if got != c.Want {
+			t.Errorf("args: %v, got: %d, want: %d", c.Args, got, c.Want)
+		}
+	}
+}
+
+func TestBGSCommands(t *testing.T) {
+	cases := []*Command{
+		{
+			Name: CommandNamePlayBGS,
+			Args: &CommandArgsPlayBGS{
+				Channel:  2,
+				Name:     "rain",
+				Volume:   60,
+				FadeTime: 30,
+			},
+		},
+		{
+			Name: CommandNameStopBGS,
+			Args: &CommandArgsStopBGS{
+				Channel:  2,
+				FadeTime: 30,
+			},
+		},
+		{
+			Name: CommandNameFadeBGS,
+			Args: &CommandArgsFadeBGS{
+				Channel: 1,
+				Volume:  20,
+				Time:    60,
+				Wait:    true,
+			},
+		},
+	}
+	for _, c := range cases {
+		b, err := msgpack.Marshal(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var c2 *Command
+		if err := msgpack.Unmarshal(b, &c2); err != nil {
+			t.Fatal(err)
+		}
+		if c2.Name != c.Name {
+			t.Errorf("got: %s, want: %s", c2.Name, c.Name)
+		}
+		if !reflect.DeepEqual(c2.Args, c.Args) {
+			t.Errorf("%s: got: %#v, want: %#v", c.Name, c2.Args, c.Args)
+		}
+	}
+}
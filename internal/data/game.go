@@ -70,11 +70,20 @@ type MessageStyle struct {
 	TypingEffectDelay int            `msgpack:"typingEffectDelay"`
 	SoundEffect       string         `msgpack:"soundEffect"`
 	CharacterAnim     *CharacterAnim `msgpack:"characterAnim"`
+
+	// Font is the name of a font file under images/fonts. An empty string means the default fonts.
+	Font string `msgpack:"font"`
 }
 
 type AssetMetadata struct {
 	PassageTypes []PassageType `msgpack:"passageTypes"`
 	IsAutoTile   bool          `msgpack:"isAutoTile"`
+
+	// LoopStart and LoopEnd are the loop points of audio in samples (44100 Hz).
+	// The part before LoopStart is an intro that is played only once.
+	// LoopEnd 0 means the end of the audio.
+	LoopStart int64 `msgpack:"loopStart"`
+	LoopEnd   int64 `msgpack:"loopEnd"`
 }
 
 type FinishTriggerType string
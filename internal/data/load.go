@@ -28,6 +28,8 @@ import (
 
 var assetDirs = []string{
 	filepath.Join("audio", "bgm"),
+	filepath.Join("audio", "bgs"),
+	filepath.Join("audio", "voice"),
 	filepath.Join("audio", "se"),
 	filepath.Join("audio", "se", "system"),
 	filepath.Join("images", "backgrounds"),
@@ -47,6 +49,7 @@ var assetDirs = []string{
 	filepath.Join("images", "tilesets", "autotiles"),
 	filepath.Join("images", "tilesets", "objects"),
 	filepath.Join("images", "titles"),
+	filepath.Join("images", "weather"),
 }
 
 func min(a, b int) int {
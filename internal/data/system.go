@@ -27,9 +27,27 @@ type System struct {
 	GameName           UUID                `msgpack:"gameName"`
 	ScreenshotMessage  UUID                `msgpack:"screenshotMessage"`
 	TitleTextColor     string              `msgpack:"titleTextColor"`
+	TitleFont          string              `msgpack:"titleFont"`
+	Fonts              []*FontSetting      `msgpack:"fonts"`
 	Switches           []*VariableData     `msgpack:"switches"`
 	Variables          []*VariableData     `msgpack:"variables"`
 	Vibration          bool                `msgpack:"vibration"`
+	Ducking            *Ducking            `msgpack:"ducking"`
+}
+
+// FontSetting is a fallback chain of TrueType or OpenType fonts under images/fonts for a language.
+// The fonts are tried in order, and then the built-in bitmap font is used.
+// If Language is undefined, the fonts are used for all the languages after the language's own fonts.
+type FontSetting struct {
+	Language Language `msgpack:"language"`
+	Fonts    []string `msgpack:"fonts"`
+}
+
+// Ducking is a setting to attenuate BGM and BGSs while messages are shown.
+type Ducking struct {
+	Volume      int `msgpack:"volume"`
+	AttackTime  int `msgpack:"attackTime"`
+	ReleaseTime int `msgpack:"releaseTime"`
 }
 
 type InitialPlayerState struct {
@@ -0,0 +1,165 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package font
+
+// arabicForms is a table of Arabic letters and their presentation forms.
+// Each entry is the isolated, final, initial and medial forms. 0 means the form doesn't exist.
+var arabicForms = map[rune][4]rune{
+	0x0621: {0xfe80, 0, 0, 0},
+	0x0622: {0xfe81, 0xfe82, 0, 0},
+	0x0623: {0xfe83, 0xfe84, 0, 0},
+	0x0624: {0xfe85, 0xfe86, 0, 0},
+	0x0625: {0xfe87, 0xfe88, 0, 0},
+	0x0626: {0xfe89, 0xfe8a, 0xfe8b, 0xfe8c},
+	0x0627: {0xfe8d, 0xfe8e, 0, 0},
+	0x0628: {0xfe8f, 0xfe90, 0xfe91, 0xfe92},
+	0x0629: {0xfe93, 0xfe94, 0, 0},
+	0x062a: {0xfe95, 0xfe96, 0xfe97, 0xfe98},
+	0x062b: {0xfe99, 0xfe9a, 0xfe9b, 0xfe9c},
+	0x062c: {0xfe9d, 0xfe9e, 0xfe9f, 0xfea0},
+	0x062d: {0xfea1, 0xfea2, 0xfea3, 0xfea4},
+	0x062e: {0xfea5, 0xfea6, 0xfea7, 0xfea8},
+	0x062f: {0xfea9, 0xfeaa, 0, 0},
+	0x0630: {0xfeab, 0xfeac, 0, 0},
+	0x0631: {0xfead, 0xfeae, 0, 0},
+	0x0632: {0xfeaf, 0xfeb0, 0, 0},
+	0x0633: {0xfeb1, 0xfeb2, 0xfeb3, 0xfeb4},
+	0x0634: {0xfeb5, 0xfeb6, 0xfeb7, 0xfeb8},
+	0x0635: {0xfeb9, 0xfeba, 0xfebb, 0xfebc},
+	0x0636: {0xfebd, 0xfebe, 0xfebf, 0xfec0},
+	0x0637: {0xfec1, 0xfec2, 0xfec3, 0xfec4},
+	0x0638: {0xfec5, 0xfec6, 0xfec7, 0xfec8},
+	0x0639: {0xfec9, 0xfeca, 0xfecb, 0xfecc},
+	0x063a: {0xfecd, 0xfece, 0xfecf, 0xfed0},
+	0x0640: {0x0640, 0x0640, 0x0640, 0x0640},
+	0x0641: {0xfed1, 0xfed2, 0xfed3, 0xfed4},
+	0x0642: {0xfed5, 0xfed6, 0xfed7, 0xfed8},
+	0x0643: {0xfed9, 0xfeda, 0xfedb, 0xfedc},
+	0x0644: {0xfedd, 0xfede, 0xfedf, 0xfee0},
+	0x0645: {0xfee1, 0xfee2, 0xfee3, 0xfee4},
+	0x0646: {0xfee5, 0xfee6, 0xfee7, 0xfee8},
+	0x0647: {0xfee9, 0xfeea, 0xfeeb, 0xfeec},
+	0x0648: {0xfeed, 0xfeee, 0, 0},
+	0x0649: {0xfeef, 0xfef0, 0xfbe8, 0xfbe9},
+	0x064a: {0xfef1, 0xfef2, 0xfef3, 0xfef4},
+
+	// Persian letters
+	0x067e: {0xfb56, 0xfb57, 0xfb58, 0xfb59},
+	0x0686: {0xfb7a, 0xfb7b, 0xfb7c, 0xfb7d},
+	0x0698: {0xfb8a, 0xfb8b, 0, 0},
+	0x06a9: {0xfb8e, 0xfb8f, 0xfb90, 0xfb91},
+	0x06af: {0xfb92, 0xfb93, 0xfb94, 0xfb95},
+	0x06cc: {0xfbfc, 0xfbfd, 0xfbfe, 0xfbff},
+}
+
+// lamAlefForms is a table of the ligatures of Lam and Alef variants.
+// Each entry is the isolated and final forms.
+var lamAlefForms = map[rune][2]rune{
+	0x0622: {0xfef5, 0xfef6},
+	0x0623: {0xfef7, 0xfef8},
+	0x0625: {0xfef9, 0xfefa},
+	0x0627: {0xfefb, 0xfefc},
+}
+
+const (
+	arabicFormIsolated = iota
+	arabicFormFinal
+	arabicFormInitial
+	arabicFormMedial
+)
+
+const arabicLam = 0x0644
+
+// isArabicTransparent reports whether r is a combining mark like harakat that doesn't affect joining.
+func isArabicTransparent(r rune) bool {
+	return (0x064b <= r && r <= 0x065f) || r == 0x0670
+}
+
+func hasArabic(rs []rune) bool {
+	for _, r := range rs {
+		if 0x0600 <= r && r <= 0x06ff {
+			return true
+		}
+	}
+	return false
+}
+
+// joinsNext reports whether r connects to the next letter.
+func joinsNext(r rune) bool {
+	f, ok := arabicForms[r]
+	return ok && f[arabicFormInitial] != 0
+}
+
+// joinsPrev reports whether r connects to the previous letter.
+func joinsPrev(r rune) bool {
+	f, ok := arabicForms[r]
+	return ok && f[arabicFormFinal] != 0
+}
+
+// shapeArabic replaces Arabic letters with their contextual presentation forms.
+// The length is kept. The second letter of a ligature is replaced with 0.
+func shapeArabic(rs []rune) []rune {
+	if !hasArabic(rs) {
+		return rs
+	}
+
+	// neighbor returns the next non-transparent rune in the direction d.
+	neighbor := func(i, d int) rune {
+		for j := i + d; 0 <= j && j < len(rs); j += d {
+			if !isArabicTransparent(rs[j]) {
+				return rs[j]
+			}
+		}
+		return 0
+	}
+
+	result := make([]rune, len(rs))
+	for i := 0; i < len(rs); i++ {
+		r := rs[i]
+		forms, ok := arabicForms[r]
+		if !ok {
+			result[i] = r
+			continue
+		}
+
+		prev := joinsNext(neighbor(i, -1)) && joinsPrev(r)
+
+		if r == arabicLam && i+1 < len(rs) {
+			if lf, ok := lamAlefForms[rs[i+1]]; ok {
+				if prev {
+					result[i] = lf[1]
+				} else {
+					result[i] = lf[0]
+				}
+				result[i+1] = 0
+				i++
+				continue
+			}
+		}
+
+		next := joinsNext(r) && joinsPrev(neighbor(i, 1))
+		f := arabicFormIsolated
+		switch {
+		case prev && next:
+			f = arabicFormMedial
+		case prev:
+			f = arabicFormFinal
+		case next:
+			f = arabicFormInitial
+		}
+		result[i] = forms[f]
+	}
+	return result
+}
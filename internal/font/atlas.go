@@ -0,0 +1,257 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package font
+
+import (
+	"image"
+	"image/color"
+	"sync"
+
+	"github.com/golang/groupcache/lru"
+	"github.com/hajimehoshi/ebiten"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	// atlasPageSize is the minimum size of an atlas page image.
+	atlasPageSize = 1024
+
+	// atlasMinCellSize is the minimum size of a glyph cell.
+	atlasMinCellSize = 16
+
+	// atlasMaxPages is the maximum number of the pages for each cell size.
+	// When all the pages are full, the least recently used glyph is evicted.
+	atlasMaxPages = 2
+
+	// atlasMaxEntries is the maximum number of the cached glyphs including glyphs without pixels.
+	atlasMaxEntries = 4096
+)
+
+// atlasKey is a key of a glyph. A face already has its scale.
+type atlasKey struct {
+	face font.Face
+	r    rune
+}
+
+type atlasPage struct {
+	image    *ebiten.Image
+	cellSize int
+	free     []int
+}
+
+func (p *atlasPage) cellRect(cell int) image.Rectangle {
+	n := p.size() / p.cellSize
+	x := (cell % n) * p.cellSize
+	y := (cell / n) * p.cellSize
+	return image.Rect(x, y, x+p.cellSize, y+p.cellSize)
+}
+
+func (p *atlasPage) size() int {
+	w, _ := p.image.Size()
+	return w
+}
+
+type atlasEntry struct {
+	// page is nil when the glyph has no pixels like a space.
+	page   *atlasPage
+	cell   int
+	bounds fixed.Rectangle26_6
+}
+
+// atlasCells is a set of pages that have cells of the same size.
+type atlasCells struct {
+	cellSize int
+	pages    []*atlasPage
+	entries  *lru.Cache
+}
+
+// glyphAtlas is a glyph cache that packs glyphs into shared images.
+//
+// Unlike ebiten's text package, which creates an image for each glyph and keeps up to 512 glyphs per face,
+// the glyph atlas has a fixed number of pages and reuses their cells.
+// Texts with many kinds of glyphs like Japanese ones don't allocate or dispose images while they are shown.
+type glyphAtlas struct {
+	cells map[int]*atlasCells
+
+	// entries are all the cached glyphs. The glyphs evicted from entries are also removed from their cells.
+	entries *lru.Cache
+}
+
+var (
+	theGlyphAtlas = newGlyphAtlas()
+	glyphAtlasM   sync.Mutex
+)
+
+func newGlyphAtlas() *glyphAtlas {
+	a := &glyphAtlas{
+		cells:   map[int]*atlasCells{},
+		entries: lru.New(atlasMaxEntries),
+	}
+	a.entries.OnEvicted = func(key lru.Key, value interface{}) {
+		if e := value.(*atlasEntry); e.page != nil {
+			a.cells[e.page.cellSize].entries.Remove(key)
+		}
+	}
+	return a
+}
+
+func atlasCellSize(width, height int) int {
+	s := atlasMinCellSize
+	for s < width || s < height {
+		s *= 2
+	}
+	return s
+}
+
+func (c *atlasCells) allocate() (*atlasPage, int) {
+	for _, p := range c.pages {
+		if len(p.free) > 0 {
+			cell := p.free[len(p.free)-1]
+			p.free = p.free[:len(p.free)-1]
+			return p, cell
+		}
+	}
+
+	if len(c.pages) < atlasMaxPages {
+		size := atlasPageSize
+		if size < c.cellSize {
+			size = c.cellSize
+		}
+		img, _ := ebiten.NewImage(size, size, ebiten.FilterDefault)
+		p := &atlasPage{
+			image:    img,
+			cellSize: c.cellSize,
+		}
+		n := size / c.cellSize
+		for i := n*n - 1; i >= 0; i-- {
+			p.free = append(p.free, i)
+		}
+		c.pages = append(c.pages, p)
+		return c.allocate()
+	}
+
+	// OnEvicted returns the cell to the page.
+	c.entries.RemoveOldest()
+	return c.allocate()
+}
+
+func (a *glyphAtlas) entry(face font.Face, r rune) *atlasEntry {
+	k := atlasKey{
+		face: face,
+		r:    r,
+	}
+	if v, ok := a.entries.Get(k); ok {
+		e := v.(*atlasEntry)
+		if e.page != nil {
+			// Mark the glyph as recently used in its cells too.
+			a.cells[e.page.cellSize].entries.Get(k)
+		}
+		return e
+	}
+
+	b, _, ok := face.GlyphBounds(r)
+	w, h := (b.Max.X - b.Min.X).Ceil(), (b.Max.Y - b.Min.Y).Ceil()
+	if !ok || w <= 0 || h <= 0 {
+		e := &atlasEntry{}
+		a.entries.Add(k, e)
+		return e
+	}
+
+	s := atlasCellSize(w, h)
+	c, ok := a.cells[s]
+	if !ok {
+		c = &atlasCells{
+			cellSize: s,
+			entries:  lru.New(0),
+		}
+		c.entries.OnEvicted = func(key lru.Key, value interface{}) {
+			e := value.(*atlasEntry)
+			e.page.free = append(e.page.free, e.cell)
+			a.entries.Remove(key)
+		}
+		a.cells[s] = c
+	}
+
+	p, cell := c.allocate()
+
+	// Rasterize the glyph into the whole cell so that the previous glyph is overwritten.
+	rgba := image.NewRGBA(image.Rect(0, 0, s, s))
+	d := font.Drawer{
+		Dst:  rgba,
+		Src:  image.White,
+		Face: face,
+		Dot:  fixed.Point26_6{X: -b.Min.X, Y: -b.Min.Y},
+	}
+	d.DrawString(string(r))
+	img, _ := ebiten.NewImageFromImage(rgba, ebiten.FilterDefault)
+	cr := p.cellRect(cell)
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(cr.Min.X), float64(cr.Min.Y))
+	op.CompositeMode = ebiten.CompositeModeCopy
+	p.image.DrawImage(img, op)
+	img.Dispose()
+
+	e := &atlasEntry{
+		page:   p,
+		cell:   cell,
+		bounds: b,
+	}
+	c.entries.Add(k, e)
+	a.entries.Add(k, e)
+	return e
+}
+
+func colorToColorM(clr color.Color) ebiten.ColorM {
+	cm := ebiten.ColorM{}
+	r, g, b, a := clr.RGBA()
+	if a == 0 {
+		cm.Scale(0, 0, 0, 0)
+		return cm
+	}
+	cm.Scale(float64(r)/float64(a), float64(g)/float64(a), float64(b)/float64(a), float64(a)/0xffff)
+	return cm
+}
+
+func fixedToFloat64(x fixed.Int26_6) float64 {
+	return float64(x) / (1 << 6)
+}
+
+// drawGlyphs draws the text with the face at the dot (x, y) as ebiten's text.Draw does.
+func drawGlyphs(dst *ebiten.Image, text string, face font.Face, x, y int, clr color.Color) {
+	glyphAtlasM.Lock()
+	defer glyphAtlasM.Unlock()
+
+	cm := colorToColorM(clr)
+	fx := fixed.I(x)
+	prev := rune(-1)
+	for _, r := range text {
+		if prev >= 0 {
+			fx += face.Kern(prev, r)
+		}
+		if e := theGlyphAtlas.entry(face, r); e.page != nil {
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Translate(fixedToFloat64(fx+e.bounds.Min.X), fixedToFloat64(fixed.I(y)+e.bounds.Min.Y))
+			op.ColorM = cm
+			cr := e.page.cellRect(e.cell)
+			w, h := (e.bounds.Max.X - e.bounds.Min.X).Ceil(), (e.bounds.Max.Y - e.bounds.Min.Y).Ceil()
+			src := image.Rect(cr.Min.X, cr.Min.Y, cr.Min.X+w, cr.Min.Y+h)
+			dst.DrawImage(e.page.image.SubImage(src).(*ebiten.Image), op)
+		}
+		a, _ := face.GlyphAdvance(r)
+		fx += a
+		prev = r
+	}
+}
@@ -0,0 +1,339 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package font
+
+import (
+	"sort"
+	"unicode"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/bidi"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/lang"
+)
+
+var mirroredRunes = map[rune]rune{
+	'(': ')',
+	')': '(',
+	'[': ']',
+	']': '[',
+	'{': '}',
+	'}': '{',
+	'<': '>',
+	'>': '<',
+	'«': '»',
+	'»': '«',
+}
+
+// bracketPairsMax is the maximum depth of nested brackets (BD16).
+const bracketPairsMax = 63
+
+var openingBrackets = map[rune]rune{
+	'(': ')',
+	'[': ']',
+	'{': '}',
+}
+
+// bracketPairs returns the indices of the paired brackets in the order of the opening brackets (BD16).
+func bracketPairs(rs []rune) [][2]int {
+	type opening struct {
+		closing rune
+		index   int
+	}
+	var stack []opening
+	var pairs [][2]int
+	for i, r := range rs {
+		if c, ok := openingBrackets[r]; ok {
+			if len(stack) == bracketPairsMax {
+				break
+			}
+			stack = append(stack, opening{c, i})
+			continue
+		}
+		for j := len(stack) - 1; j >= 0; j-- {
+			if stack[j].closing != r {
+				continue
+			}
+			pairs = append(pairs, [2]int{stack[j].index, i})
+			stack = stack[:j]
+			break
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i][0] < pairs[j][0]
+	})
+	return pairs
+}
+
+type bidiType int
+
+const (
+	bidiTypeL bidiType = iota
+	bidiTypeR
+	bidiTypeNumber
+	bidiTypeNeutral
+)
+
+func isRTL(l language.Tag) bool {
+	return lang.IsRTL(l)
+}
+
+func isRTLRune(r rune) bool {
+	if r < 0x0590 {
+		return false
+	}
+	p, _ := bidi.LookupRune(r)
+	c := p.Class()
+	return c == bidi.R || c == bidi.AL
+}
+
+func hasRTLRune(rs []rune) bool {
+	for _, r := range rs {
+		if isRTLRune(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func bidiTypeOf(r rune) bidiType {
+	p, _ := bidi.LookupRune(r)
+	switch p.Class() {
+	case bidi.L:
+		return bidiTypeL
+	case bidi.R, bidi.AL:
+		return bidiTypeR
+	case bidi.EN, bidi.AN:
+		return bidiTypeNumber
+	}
+	return bidiTypeNeutral
+}
+
+// bidiLevels returns the embedding levels of the runes in a line.
+// This is a simplified version of the Unicode Bidirectional Algorithm (UAX #9) without explicit embeddings.
+func bidiLevels(rs []rune, rtl bool) []int {
+	baseType := bidiTypeL
+	if rtl {
+		baseType = bidiTypeR
+	}
+
+	types := make([]bidiType, len(rs))
+	for i, r := range rs {
+		p, _ := bidi.LookupRune(r)
+		if p.Class() == bidi.NSM && i > 0 {
+			// A non-spacing mark takes the type of the previous rune.
+			types[i] = types[i-1]
+			continue
+		}
+		types[i] = bidiTypeOf(r)
+	}
+
+	// Numbers after a left-to-right letter are treated as left-to-right (W7).
+	// Otherwise, numbers work as right-to-left for the neutrals (N1).
+	strong := baseType
+	for i, t := range types {
+		switch t {
+		case bidiTypeL, bidiTypeR:
+			strong = t
+		case bidiTypeNumber:
+			if strong == bidiTypeL {
+				types[i] = bidiTypeL
+			}
+		}
+	}
+
+	strongOf := func(t bidiType) bidiType {
+		if t == bidiTypeNumber {
+			return bidiTypeR
+		}
+		return t
+	}
+
+	// Paired brackets take the same direction (N0).
+	for _, p := range bracketPairs(rs) {
+		e, o := baseType, bidiTypeR
+		if baseType == bidiTypeR {
+			o = bidiTypeL
+		}
+		hasE, hasO := false, false
+		for _, t := range types[p[0]+1 : p[1]] {
+			switch strongOf(t) {
+			case e:
+				hasE = true
+			case o:
+				hasO = true
+			}
+		}
+		t := bidiTypeNeutral
+		switch {
+		case hasE:
+			t = e
+		case hasO:
+			// The brackets take the opposite direction only when the context before them has the same direction.
+			t = e
+			for i := p[0] - 1; i >= 0; i-- {
+				if st := strongOf(types[i]); st != bidiTypeNeutral {
+					if st == o {
+						t = o
+					}
+					break
+				}
+			}
+		}
+		if t != bidiTypeNeutral {
+			types[p[0]] = t
+			types[p[1]] = t
+		}
+	}
+
+	// Neutrals take the direction of the surrounding strong types if both are the same (N1).
+	// Otherwise, neutrals take the base direction (N2).
+	for i := 0; i < len(types); {
+		if types[i] != bidiTypeNeutral {
+			i++
+			continue
+		}
+		j := i
+		for j < len(types) && types[j] == bidiTypeNeutral {
+			j++
+		}
+		prev := baseType
+		if i > 0 {
+			prev = strongOf(types[i-1])
+		}
+		next := baseType
+		if j < len(types) {
+			next = strongOf(types[j])
+		}
+		t := baseType
+		if prev == next {
+			t = prev
+		}
+		for k := i; k < j; k++ {
+			types[k] = t
+		}
+		i = j
+	}
+
+	base := 0
+	if rtl {
+		base = 1
+	}
+	levels := make([]int, len(rs))
+	for i, t := range types {
+		switch t {
+		case bidiTypeL:
+			levels[i] = base + base%2
+		case bidiTypeR:
+			levels[i] = base + 1 - base%2
+		case bidiTypeNumber:
+			levels[i] = base + 2 - base%2
+		}
+	}
+
+	// Trailing whitespaces take the base level (L1).
+	for i := len(rs) - 1; i >= 0 && unicode.IsSpace(rs[i]); i-- {
+		levels[i] = base
+	}
+	return levels
+}
+
+// visualOrder returns the indices of the runes in the visual order from left to right (L2).
+func visualOrder(levels []int) []int {
+	order := make([]int, len(levels))
+	max := 0
+	minOdd := -1
+	for i, l := range levels {
+		order[i] = i
+		if max < l {
+			max = l
+		}
+		if l%2 == 1 && (minOdd < 0 || l < minOdd) {
+			minOdd = l
+		}
+	}
+	if minOdd < 0 {
+		return order
+	}
+	for l := max; l >= minOdd; l-- {
+		for i := 0; i < len(order); {
+			if levels[order[i]] < l {
+				i++
+				continue
+			}
+			j := i
+			for j < len(order) && levels[order[j]] >= l {
+				j++
+			}
+			for a, b := i, j-1; a < b; a, b = a+1, b-1 {
+				order[a], order[b] = order[b], order[a]
+			}
+			i = j
+		}
+	}
+	return order
+}
+
+// visualText returns the line in the visual order with Arabic letters shaped.
+// rtl indicates whether the base direction is right-to-left.
+func visualText(line string, rtl bool) string {
+	rs := []rune(line)
+	if !rtl && !hasRTLRune(rs) {
+		return line
+	}
+	rs = shapeArabic(rs)
+	levels := bidiLevels(rs, rtl)
+	vs := make([]rune, 0, len(rs))
+	for _, i := range visualOrder(levels) {
+		r := rs[i]
+		if r == 0 {
+			continue
+		}
+		if levels[i]%2 == 1 {
+			if m, ok := mirroredRunes[r]; ok {
+				r = m
+			}
+		}
+		vs = append(vs, r)
+	}
+	return string(vs)
+}
+
+// shapedText returns the line with Arabic letters shaped in the logical order.
+// shapedText is used to measure the width.
+func shapedText(line string) string {
+	rs := []rune(line)
+	if !hasArabic(rs) {
+		return line
+	}
+	srs := shapeArabic(rs)
+	vs := make([]rune, 0, len(srs))
+	for _, r := range srs {
+		if r != 0 {
+			vs = append(vs, r)
+		}
+	}
+	return string(vs)
+}
+
+// VisualTextForTesting returns the line in the visual order with Arabic letters shaped.
+func VisualTextForTesting(line string, rtl bool) string {
+	return visualText(line, rtl)
+}
+
+// ShapedTextForTesting returns the line with Arabic letters shaped in the logical order.
+func ShapedTextForTesting(line string) string {
+	return shapedText(line)
+}
@@ -0,0 +1,138 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package font_test
+
+import (
+	"testing"
+
+	. "github.com/hajimehoshi/rpgsnack-runtime/internal/font"
+)
+
+func TestVisualText(t *testing.T) {
+	cases := []struct {
+		In   string
+		RTL  bool
+		Want string
+	}{
+		{
+			In:   "abc (def)",
+			RTL:  false,
+			Want: "abc (def)",
+		},
+		{
+			In:   "abc אבג def",
+			RTL:  false,
+			Want: "abc גבא def",
+		},
+		{
+			In:   "אבג abc",
+			RTL:  true,
+			Want: "abc גבא",
+		},
+		{
+			// Numbers keep their order in right-to-left texts.
+			In:   "אב 123 גד",
+			RTL:  true,
+			Want: "דג 123 בא",
+		},
+		{
+			In:   "abc אב 12",
+			RTL:  false,
+			Want: "abc 12 בא",
+		},
+		{
+			// Brackets are mirrored in right-to-left runs.
+			In:   "א(ב)",
+			RTL:  true,
+			Want: "(ב)א",
+		},
+		{
+			// Paired brackets take the direction of their content and context.
+			In:   "a(b)",
+			RTL:  true,
+			Want: "a(b)",
+		},
+		{
+			In:   "א(abc)",
+			RTL:  true,
+			Want: "(abc)א",
+		},
+		{
+			In:   "ab (אב) cd",
+			RTL:  false,
+			Want: "ab (בא) cd",
+		},
+		{
+			// Seen, Lam-Alef and Meem are shaped and reversed.
+			In:   "سلام",
+			RTL:  true,
+			Want: "\ufee1\ufefc\ufeb3",
+		},
+	}
+	for _, c := range cases {
+		got := VisualTextForTesting(c.In, c.RTL)
+		if got != c.Want {
+			t.Errorf("VisualTextForTesting(%q, %v): got: %q, want: %q", c.In, c.RTL, got, c.Want)
+		}
+	}
+}
+
+func TestShapedText(t *testing.T) {
+	cases := []struct {
+		In   string
+		Want string
+	}{
+		{
+			In:   "abc",
+			Want: "abc",
+		},
+		{
+			// Lam-Alef
+			In:   "لا",
+			Want: "\ufefb",
+		},
+		{
+			// Beh and the final form of Lam-Alef
+			In:   "بلا",
+			Want: "\ufe91\ufefc",
+		},
+		{
+			// Lam-Alef with Hamza above
+			In:   "لأ",
+			Want: "\ufef7",
+		},
+		{
+			// Beh, Yeh and Teh in the initial, medial and final forms
+			In:   "بيت",
+			Want: "\ufe91\ufef4\ufe96",
+		},
+		{
+			// Harakat don't break joining.
+			In:   "بَب",
+			Want: "\ufe91\u064e\ufe90",
+		},
+		{
+			// Alef doesn't join the next letter.
+			In:   "اب",
+			Want: "\ufe8d\ufe8f",
+		},
+	}
+	for _, c := range cases {
+		got := ShapedTextForTesting(c.In)
+		if got != c.Want {
+			t.Errorf("ShapedTextForTesting(%q): got: %q, want: %q", c.In, got, c.Want)
+		}
+	}
+}
@@ -52,3 +52,15 @@ func boundString(face font.Face, str string) (*fixed.Rectangle26_6, fixed.Int26_
 
 	return entry.bounds, entry.advance
 }
+
+// boundRubyString returns the advance of the base text with the ruby text.
+// The base and the ruby are a unit and the wider one decides the advance.
+func boundRubyString(face font.Face, base, ruby string) fixed.Int26_6 {
+	_, a := boundString(face, base)
+	_, ra := boundString(face, ruby)
+	ra = fixed.Int26_6(float64(ra) * rubyScale)
+	if a < ra {
+		return ra
+	}
+	return a
+}
@@ -15,18 +15,141 @@
 package font
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/hajimehoshi/bitmapfont"
 	"golang.org/x/image/font"
+	"golang.org/x/image/font/sfnt"
 	"golang.org/x/text/language"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/assets"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
 )
 
+// fontsDir is the asset directory for TrueType and OpenType fonts.
+const fontsDir = "images/fonts/"
+
 var (
 	bfFaces = map[int]font.Face{}
 	scFaces = map[int]font.Face{}
 	tcFaces = map[int]font.Face{}
+
+	customFonts     = map[string]*sfnt.Font{}
+	customFallbacks []*data.FontSetting
+	customFaces     = map[customFaceKey]font.Face{}
 )
 
+type customFaceKey struct {
+	name  string
+	scale int
+	lang  language.Tag
+}
+
+// LoadFonts loads the TrueType and OpenType fonts used in the game from the assets.
+func LoadFonts(game *data.Game) error {
+	customFonts = map[string]*sfnt.Font{}
+	customFallbacks = nil
+	customFaces = map[customFaceKey]font.Face{}
+
+	var names []string
+	if s := game.System; s != nil {
+		for _, f := range s.Fonts {
+			names = append(names, f.Fonts...)
+		}
+		names = append(names, s.TitleFont)
+		customFallbacks = s.Fonts
+	}
+	for _, s := range game.MessageStyles {
+		names = append(names, s.Font)
+	}
+
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		if _, ok := customFonts[name]; ok {
+			continue
+		}
+		if !assets.Exists(fontsDir + name) {
+			return fmt.Errorf("font: font not found: %s", name)
+		}
+		f, err := sfnt.Parse(assets.GetResource(fontsDir + name))
+		if err != nil {
+			return fmt.Errorf("font: parsing %s failed: %v", name, err)
+		}
+		customFonts[name] = f
+	}
+	return nil
+}
+
+// fallbackFontNames returns the names of the custom fonts to try in order for the language.
+// name comes first, then the fonts for the language, and then the fonts for any languages.
+func fallbackFontNames(name string, lang language.Tag, settings []*data.FontSetting) []string {
+	var names []string
+	if name != "" {
+		names = append(names, name)
+	}
+	base, _ := lang.Base()
+	for _, und := range []bool{false, true} {
+		for _, s := range settings {
+			l := language.Tag(s.Language)
+			if (l == language.Und) != und {
+				continue
+			}
+			if !und {
+				if b, _ := l.Base(); b != base {
+					continue
+				}
+			}
+			names = append(names, s.Fonts...)
+		}
+	}
+	return names
+}
+
+func FallbackFontNamesForTesting(name string, lang language.Tag, settings []*data.FontSetting) []string {
+	return fallbackFontNames(name, lang, settings)
+}
+
 func face(scale int, lang language.Tag) font.Face {
+	return namedFace("", scale, lang)
+}
+
+// namedFace returns the face that tries the custom font of name, the custom fonts for the language,
+// and then the bitmap font for the language.
+// If name is empty, only the fonts for the language are used.
+func namedFace(name string, scale int, lang language.Tag) font.Face {
+	names := fallbackFontNames(name, lang, customFallbacks)
+	if len(names) == 0 {
+		return bitmapFace(scale, lang)
+	}
+
+	k := customFaceKey{
+		name:  strings.Join(names, "\n"),
+		scale: scale,
+		lang:  lang,
+	}
+	if f, ok := customFaces[k]; ok {
+		return f
+	}
+	var faces []font.Face
+	for _, n := range names {
+		f, ok := customFonts[n]
+		if !ok {
+			continue
+		}
+		faces = append(faces, newSFNTFace(f, scale))
+	}
+	faces = append(faces, bitmapFace(scale, lang))
+	f := &fallbackFace{faces}
+	customFaces[k] = f
+	return f
+}
+
+// bitmapFace returns the bitmap font for the language.
+// Bitmap fonts are scaled by pixels to keep the pixel-art look.
+func bitmapFace(scale int, lang language.Tag) font.Face {
 	switch lang {
 	case language.SimplifiedChinese:
 		f, ok := scFaces[scale]
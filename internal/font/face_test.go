@@ -0,0 +1,80 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package font_test
+
+import (
+	"reflect"
+	"testing"
+
+	"golang.org/x/text/language"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+	. "github.com/hajimehoshi/rpgsnack-runtime/internal/font"
+)
+
+func TestFallbackFontNames(t *testing.T) {
+	settings := []*data.FontSetting{
+		{
+			Language: data.Language(language.Und),
+			Fonts:    []string{"any.ttf"},
+		},
+		{
+			Language: data.Language(language.Japanese),
+			Fonts:    []string{"ja.ttf", "ja2.otf"},
+		},
+		{
+			Language: data.Language(language.TraditionalChinese),
+			Fonts:    []string{"zh.ttf"},
+		},
+	}
+	cases := []struct {
+		Name     string
+		Lang     language.Tag
+		Settings []*data.FontSetting
+		Out      []string
+	}{
+		{
+			Name:     "",
+			Lang:     language.Japanese,
+			Settings: settings,
+			Out:      []string{"ja.ttf", "ja2.otf", "any.ttf"},
+		},
+		{
+			Name:     "title.ttf",
+			Lang:     language.English,
+			Settings: settings,
+			Out:      []string{"title.ttf", "any.ttf"},
+		},
+		{
+			// The fonts are chosen by the base language.
+			Name:     "",
+			Lang:     language.SimplifiedChinese,
+			Settings: settings,
+			Out:      []string{"zh.ttf", "any.ttf"},
+		},
+		{
+			Name:     "",
+			Lang:     language.English,
+			Settings: nil,
+			Out:      nil,
+		},
+	}
+	for _, c := range cases {
+		got := FallbackFontNamesForTesting(c.Name, c.Lang, c.Settings)
+		if !reflect.DeepEqual(got, c.Out) {
+			t.Errorf("FallbackFontNamesForTesting(%q, %s): got: %v, want: %v", c.Name, c.Lang, got, c.Out)
+		}
+	}
+}
@@ -23,7 +23,6 @@ import (
 
 	"github.com/golang/groupcache/lru"
 	"github.com/hajimehoshi/ebiten"
-	"github.com/hajimehoshi/ebiten/text"
 	"golang.org/x/image/math/fixed"
 	"golang.org/x/text/language"
 
@@ -42,6 +41,7 @@ type floatScaleImageCacheKey struct {
 	color color.Color
 	lang  language.Tag
 	align data.TextAlign
+	font  string
 }
 
 // floatScaleImageCache is an image cache with scales and its text.
@@ -56,10 +56,14 @@ const (
 )
 
 func MeasureSize(text string) (int, int) {
+	return measureSize(text, "")
+}
+
+func measureSize(text string, fontName string) (int, int) {
 	w := fixed.I(0)
 	h := fixed.I(0)
 	for _, l := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
-		b, _ := boundString(face(1, lang.Get()), l)
+		b, _ := boundString(namedFace(fontName, 1, lang.Get()), shapedText(l))
 		nw := b.Max.X - b.Min.X
 		if nw > w {
 			w = nw
@@ -80,7 +84,7 @@ var scratchPadM sync.Mutex
 func DrawTextToScratchPad(str string, scale float64, lang language.Tag) {
 	scratchPadM.Lock()
 	f := face(int(math.Ceil(scale)), lang)
-	text.Draw(scratchPad, str, f, 0, 0, color.White)
+	drawGlyphs(scratchPad, str, f, 0, 0, color.White)
 	scratchPadM.Unlock()
 }
 
@@ -95,6 +99,12 @@ type DrawTextOptions struct {
 	UseRuneCount bool
 	RuneCount    int
 	Language     language.Tag
+
+	// Time is the number of frames for animated effects at DrawRichText.
+	Time int
+
+	// Font is the name of a font file under images/fonts. An empty string means the default fonts.
+	Font string
 }
 
 func DrawText(screen *ebiten.Image, str string, ox, oy int, op *DrawTextOptions) {
@@ -121,22 +131,23 @@ func DrawText(screen *ebiten.Image, str string, ox, oy int, op *DrawTextOptions)
 	}
 
 	if isInteger(scale) {
-		drawTextLangIntScale(screen, str, ox, oy, int(scale), ta, op.Color, c, l)
+		drawTextLangIntScale(screen, str, ox, oy, int(scale), ta, op.Color, c, l, op.Font)
 		return
 	}
-	drawTextLangFloatScale(screen, str, ox, oy, scale, ta, op.Color, c, l)
+	drawTextLangFloatScale(screen, str, ox, oy, scale, ta, op.Color, c, l, op.Font)
 }
 
-func drawTextLangFloatScale(screen *ebiten.Image, str string, ox, oy int, scale float64, textAlign data.TextAlign, color color.Color, displayTextRuneCount int, lang language.Tag) {
+func drawTextLangFloatScale(screen *ebiten.Image, str string, ox, oy int, scale float64, textAlign data.TextAlign, color color.Color, displayTextRuneCount int, lang language.Tag, fontName string) {
 	k := floatScaleImageCacheKey{
 		text:  str,
 		scale: scale,
 		color: color,
 		lang:  lang,
 		align: textAlign,
+		font:  fontName,
 	}
 	var img *ebiten.Image
-	w, h := MeasureSize(str)
+	w, h := measureSize(str, fontName)
 	if cached, ok := floatScaleImageCache.Get(k); ok {
 		img = cached.(*ebiten.Image)
 	} else {
@@ -153,7 +164,7 @@ func drawTextLangFloatScale(screen *ebiten.Image, str string, ox, oy int, scale
 		case data.TextAlignRight:
 			x += w * scalei
 		}
-		drawTextLangIntScale(src, str, x, y, scalei, textAlign, color, displayTextRuneCount, lang)
+		drawTextLangIntScale(src, str, x, y, scalei, textAlign, color, displayTextRuneCount, lang, fontName)
 
 		// dst is an image that has texts scaled by `scale`.
 		dst, _ := ebiten.NewImage(int(math.Ceil(float64(w)*scale)), int(math.Ceil(float64(h)*scale)), ebiten.FilterDefault)
@@ -180,8 +191,8 @@ func drawTextLangFloatScale(screen *ebiten.Image, str string, ox, oy int, scale
 	screen.DrawImage(img, op)
 }
 
-func drawTextLangIntScale(screen *ebiten.Image, str string, ox, oy int, scale int, textAlign data.TextAlign, color color.Color, displayTextRuneCount int, lang language.Tag) {
-	f := face(scale, lang)
+func drawTextLangIntScale(screen *ebiten.Image, str string, ox, oy int, scale int, textAlign data.TextAlign, color color.Color, displayTextRuneCount int, lang language.Tag, fontName string) {
+	f := namedFace(fontName, scale, lang)
 	m := f.Metrics()
 	oy += (RenderingLineHeight*scale - m.Height.Round()) / 2
 
@@ -190,11 +201,13 @@ func drawTextLangIntScale(screen *ebiten.Image, str string, ox, oy int, scale in
 
 	lines := strings.Split(str, "\n")
 	linesToShow := strings.Split(string([]rune(str)[:displayTextRuneCount]), "\n")
+	rtl := isRTL(lang)
 
 	for i, l := range linesToShow {
 		x := ox + dotX
 		y := oy + mplusDotY*scale
-		_, a := boundString(f, lines[i])
+		line := visualText(lines[i], rtl)
+		_, a := boundString(f, line)
 		switch textAlign {
 		case data.TextAlignLeft:
 			// do nothing
@@ -206,7 +219,13 @@ func drawTextLangIntScale(screen *ebiten.Image, str string, ox, oy int, scale in
 			panic(fmt.Sprintf("font: invalid text align: %d", textAlign))
 		}
 
-		text.Draw(screen, l, f, x, y, color)
+		l = visualText(l, rtl)
+		if rtl && l != line {
+			// A partial line in a right-to-left language starts from the right end.
+			_, pa := boundString(f, l)
+			x += a.Ceil() - pa.Ceil()
+		}
+		drawGlyphs(screen, l, f, x, y, color)
 		oy += RenderingLineHeight * scale
 	}
 }
@@ -0,0 +1,412 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package font
+
+import (
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten"
+	"golang.org/x/image/font"
+	"golang.org/x/text/language"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/consts"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/lang"
+)
+
+const (
+	// rubyScale is the scale of ruby texts relative to the base texts.
+	rubyScale = 0.5
+
+	// rubyHeight is the extra height of a line with ruby texts at scale 1.
+	// Ruby texts slightly overlap with the top margin of the line.
+	rubyHeight = 7
+)
+
+type TextEffect int
+
+const (
+	TextEffectShake TextEffect = 1 << iota
+	TextEffectWave
+	TextEffectRainbow
+)
+
+// TextSpan is a part of a text with its own style.
+type TextSpan struct {
+	Text string
+
+	// Color is the text color. If Color is nil, DrawTextOptions.Color is used.
+	Color color.Color
+
+	// Scale is the scale relative to DrawTextOptions.Scale. 0 means 1.
+	Scale float64
+
+	Effect TextEffect
+
+	// Icon is an inline image. If Icon is not nil, Text is ignored and the span is counted as one rune.
+	// The icon is scaled to fit with the line height.
+	// If Color is not nil, the icon is drawn as a silhouette in Color, which is useful for shadows.
+	Icon *ebiten.Image
+
+	// Ruby is an annotation like furigana drawn above Text at a reduced scale.
+	// If Ruby is not empty, Text and Ruby are treated as one unit and counted as one rune.
+	Ruby string
+
+	// Font is the name of a font file under images/fonts. An empty string means the default fonts.
+	Font string
+}
+
+func (s *TextSpan) scale() float64 {
+	if s.Scale == 0 {
+		return 1
+	}
+	return s.Scale
+}
+
+func (s *TextSpan) face(lang language.Tag) font.Face {
+	return namedFace(s.Font, 1, lang)
+}
+
+func (s *TextSpan) isUnit() bool {
+	return s.Icon != nil || s.Ruby != ""
+}
+
+func (s *TextSpan) isPlain() bool {
+	return !s.isUnit() && s.scale() == 1
+}
+
+func (s *TextSpan) runeCount() int {
+	if s.isUnit() {
+		return 1
+	}
+	return len([]rune(s.Text))
+}
+
+// splitSpanLines splits the spans into lines.
+func splitSpanLines(spans []TextSpan) [][]TextSpan {
+	lines := [][]TextSpan{nil}
+	for _, s := range spans {
+		if s.isUnit() {
+			lines[len(lines)-1] = append(lines[len(lines)-1], s)
+			continue
+		}
+		for i, t := range strings.Split(ToValidContent(s.Text), "\n") {
+			if i > 0 {
+				lines = append(lines, nil)
+			}
+			if t == "" {
+				continue
+			}
+			s := s
+			s.Text = t
+			lines[len(lines)-1] = append(lines[len(lines)-1], s)
+		}
+	}
+	return lines
+}
+
+func spanLineScale(line []TextSpan) float64 {
+	m := 1.0
+	for _, s := range line {
+		if m < s.scale() {
+			m = s.scale()
+		}
+	}
+	return m
+}
+
+func spanLineHasRuby(line []TextSpan) bool {
+	for _, s := range line {
+		if s.Ruby != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// spanLineHeight returns the height of the line at scale 1.
+func spanLineHeight(line []TextSpan) float64 {
+	h := float64(RenderingLineHeight)
+	if spanLineHasRuby(line) {
+		h += rubyHeight
+	}
+	return h * spanLineScale(line)
+}
+
+// spanWidth returns the width of the span at scale 1.
+func spanWidth(lang language.Tag, s *TextSpan) float64 {
+	f := s.face(lang)
+	if s.Icon != nil {
+		return RenderingLineHeight * s.scale()
+	}
+	if s.Ruby != "" {
+		return float64(boundRubyString(f, s.Text, s.Ruby).Ceil()) * s.scale()
+	}
+	_, a := boundString(f, shapedText(s.Text))
+	return float64(a.Ceil()) * s.scale()
+}
+
+func spanLineWidth(lang language.Tag, line []TextSpan) float64 {
+	w := 0.0
+	for i := range line {
+		w += spanWidth(lang, &line[i])
+	}
+	return w
+}
+
+// MeasureSpans returns the size of the spans in the same unit as MeasureSize.
+func MeasureSpans(spans []TextSpan) (int, int) {
+	// Use MeasureSize for a plain text so that the result is exactly same.
+	if len(spans) == 1 && spans[0].isPlain() {
+		return measureSize(spans[0].Text, spans[0].Font)
+	}
+
+	lines := splitSpanLines(spans)
+	// Ignore the last empty line as MeasureSize does.
+	if len(lines) > 1 && len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	w := 0.0
+	h := 0.0
+	for _, l := range lines {
+		if lw := spanLineWidth(lang.Get(), l); w < lw {
+			w = lw
+		}
+		h += spanLineHeight(l)
+	}
+	return int(math.Ceil(w)), int(math.Ceil(h))
+}
+
+// baseline returns the Y position of the baseline from the top of the line in pixels.
+func baseline(scale float64, lang language.Tag) float64 {
+	si := int(math.Ceil(scale))
+	f := face(si, lang)
+	b := (RenderingLineHeight*si-f.Metrics().Height.Round())/2 + mplusDotY*si
+	return float64(b) * scale / float64(si)
+}
+
+// effectOffset returns the offset of the glyph in pixels at scale 1.
+func effectOffset(effect TextEffect, index int, time int) (float64, float64) {
+	x, y := 0.0, 0.0
+	if effect&TextEffectShake != 0 {
+		// A cheap deterministic noise so that the shadows and the edges are shaken in the same way.
+		h := uint32(index*7919+(time/2)*104729) * 2654435761
+		x += float64(int(h>>8)%3 - 1)
+		y += float64(int(h>>16)%3 - 1)
+	}
+	if effect&TextEffectWave != 0 {
+		y += 2 * math.Sin(float64(time)*0.15+float64(index)*0.6)
+	}
+	return x, y
+}
+
+func rainbowColor(index int, time int) color.Color {
+	h := math.Mod(float64(time*4+index*20), 360) / 60
+	x := 1 - math.Abs(math.Mod(h, 2)-1)
+	var r, g, b float64
+	switch int(h) {
+	case 0:
+		r, g, b = 1, x, 0
+	case 1:
+		r, g, b = x, 1, 0
+	case 2:
+		r, g, b = 0, 1, x
+	case 3:
+		r, g, b = 0, x, 1
+	case 4:
+		r, g, b = x, 0, 1
+	default:
+		r, g, b = 1, 0, x
+	}
+	return color.RGBA{uint8(r * 0xff), uint8(g * 0xff), uint8(b * 0xff), 0xff}
+}
+
+// DrawRichText draws the spans. op.TextAlign, op.UseRuneCount and op.RuneCount work as DrawText does.
+// Line breaks are counted as runes for op.RuneCount.
+func DrawRichText(screen *ebiten.Image, spans []TextSpan, ox, oy int, op *DrawTextOptions) {
+	// Use DrawText for a plain text so that the result is exactly same.
+	if len(spans) == 1 && spans[0].isPlain() && spans[0].Effect == 0 {
+		o := *op
+		if spans[0].Color != nil {
+			o.Color = spans[0].Color
+		}
+		if spans[0].Font != "" {
+			o.Font = spans[0].Font
+		}
+		DrawText(screen, spans[0].Text, ox, oy, &o)
+		return
+	}
+
+	scale := op.Scale
+	if scale == 0 {
+		scale = consts.TextScale
+	}
+
+	ta := op.TextAlign
+	if ta == *new(data.TextAlign) {
+		ta = data.TextAlignLeft
+	}
+
+	l := op.Language
+	if l == language.Und {
+		l = lang.Get()
+	}
+
+	count := -1
+	if op.UseRuneCount {
+		count = op.RuneCount
+	}
+
+	rtl := isRTL(l)
+	index := 0
+	y := float64(oy)
+	for _, line := range splitSpanLines(spans) {
+		ls := spanLineScale(line)
+		lb := baseline(scale*ls, l)
+		top := 0.0
+		if spanLineHasRuby(line) {
+			top = rubyHeight * scale * ls
+		}
+
+		x := float64(ox)
+		switch ta {
+		case data.TextAlignCenter:
+			x -= math.Ceil(spanLineWidth(l, line)*scale) / 2
+		case data.TextAlignRight:
+			x -= math.Ceil(spanLineWidth(l, line) * scale)
+		}
+
+		// starts are the indices of the spans in the logical order.
+		starts := make([]int, len(line))
+		n := index
+		for i := range line {
+			starts[i] = n
+			n += line[i].runeCount()
+		}
+
+		for k := range line {
+			// The spans are placed from the right in a right-to-left language.
+			i := k
+			if rtl {
+				i = len(line) - 1 - k
+			}
+			s := &line[i]
+			start := starts[i]
+			f := s.face(l)
+			w := spanWidth(l, s) * scale
+			if count >= 0 && count <= start {
+				x += w
+				continue
+			}
+			ss := scale * s.scale()
+			sy := y + top + lb - baseline(ss, l)
+
+			if s.Icon != nil {
+				size := RenderingLineHeight * ss
+				w, h := s.Icon.Size()
+				iop := &ebiten.DrawImageOptions{}
+				iop.GeoM.Scale(size/float64(w), size/float64(h))
+				iop.GeoM.Translate(x, y+top+RenderingLineHeight*scale*ls-size)
+				if s.Color != nil {
+					r, g, b, a := s.Color.RGBA()
+					if a > 0 {
+						iop.ColorM.Scale(0, 0, 0, float64(a)/0xffff)
+						iop.ColorM.Translate(float64(r)/float64(a), float64(g)/float64(a), float64(b)/float64(a), 0)
+					}
+				}
+				screen.DrawImage(s.Icon, iop)
+				x += size
+				continue
+			}
+
+			c := s.Color
+			if c == nil {
+				c = op.Color
+			}
+			opts := &DrawTextOptions{
+				Scale:    ss,
+				Color:    c,
+				Language: l,
+				Font:     s.Font,
+			}
+
+			if s.Ruby != "" {
+				_, ba := boundString(f, shapedText(s.Text))
+				_, ra := boundString(f, shapedText(s.Ruby))
+				bx := x + (w-float64(ba.Ceil())*ss)/2
+				rx := x + (w-float64(ra.Ceil())*ss*rubyScale)/2
+				dx, dy := effectOffset(s.Effect, start, op.Time)
+				if s.Effect&TextEffectRainbow != 0 {
+					opts.Color = rainbowColor(start, op.Time)
+				}
+				DrawText(screen, s.Text, int(bx+dx*ss), int(sy+dy*ss), opts)
+				ropts := *opts
+				ropts.Scale = ss * rubyScale
+				DrawText(screen, s.Ruby, int(rx+dx*ss), int(sy+(dy-rubyHeight)*ss), &ropts)
+				x += w
+				continue
+			}
+
+			str := s.Text
+			if count >= 0 && start+s.runeCount() > count {
+				str = string([]rune(str)[:count-start])
+			}
+
+			if s.Effect == 0 {
+				if rtl {
+					// DrawText aligns a partial text to the right end.
+					opts.TextAlign = data.TextAlignRight
+					opts.UseRuneCount = true
+					opts.RuneCount = len([]rune(str))
+					DrawText(screen, s.Text, int(x+w), int(sy), opts)
+				} else {
+					DrawText(screen, str, int(x), int(sy), opts)
+				}
+				x += w
+				continue
+			}
+
+			// Draw the glyphs one by one for the effects.
+			vs := []rune(visualText(str, rtl))
+			gx := x
+			if rtl {
+				_, a := boundString(f, string(vs))
+				gx += w - float64(a.Ceil())*ss
+			}
+			for j, r := range vs {
+				dx, dy := effectOffset(s.Effect, start+j, op.Time)
+				if s.Effect&TextEffectRainbow != 0 {
+					opts.Color = rainbowColor(start+j, op.Time)
+				}
+				a, _ := f.GlyphAdvance(r)
+				if rtl {
+					// DrawText mirrors a single bracket in a right-to-left language again.
+					if m, ok := mirroredRunes[r]; ok {
+						r = m
+					}
+				}
+				DrawText(screen, string(r), int(gx+dx*ss), int(sy+dy*ss), opts)
+				gx += float64(a.Ceil()) * ss
+			}
+			x += w
+		}
+
+		// The line break is counted as a rune.
+		index = n + 1
+		y += spanLineHeight(line) * scale
+	}
+}
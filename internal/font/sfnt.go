@@ -0,0 +1,259 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package font
+
+import (
+	"image"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+// sfntPPEM is the number of pixels in 1 em at scale 1, that matches with the bitmap fonts.
+const sfntPPEM = 12
+
+type sfntGlyph struct {
+	bounds fixed.Rectangle26_6
+	mask   *image.Alpha
+}
+
+// sfntFace is a font.Face for a TrueType or OpenType font.
+//
+// golang.org/x/image/font/opentype is not implemented in the current version, then the glyphs are rasterized here.
+type sfntFace struct {
+	font   *sfnt.Font
+	ppem   fixed.Int26_6
+	buf    sfnt.Buffer
+	glyphs map[rune]*sfntGlyph
+	m      sync.Mutex
+}
+
+func newSFNTFace(f *sfnt.Font, scale int) *sfntFace {
+	return &sfntFace{
+		font:   f,
+		ppem:   fixed.I(sfntPPEM * scale),
+		glyphs: map[rune]*sfntGlyph{},
+	}
+}
+
+func (s *sfntFace) Close() error {
+	return nil
+}
+
+func (s *sfntFace) index(r rune) (sfnt.GlyphIndex, bool) {
+	idx, err := s.font.GlyphIndex(&s.buf, r)
+	if err != nil || idx == 0 {
+		return 0, false
+	}
+	return idx, true
+}
+
+// glyph returns the rasterized glyph. The bounds are aligned with pixels.
+func (s *sfntFace) glyph(r rune) (*sfntGlyph, bool) {
+	if g, ok := s.glyphs[r]; ok {
+		return g, g != nil
+	}
+
+	idx, ok := s.index(r)
+	if !ok {
+		s.glyphs[r] = nil
+		return nil, false
+	}
+	segs, err := s.font.LoadGlyph(&s.buf, idx, s.ppem, nil)
+	if err != nil {
+		s.glyphs[r] = nil
+		return nil, false
+	}
+
+	g := &sfntGlyph{}
+	if len(segs) == 0 {
+		// A glyph without any shapes like a space.
+		g.mask = image.NewAlpha(image.Rectangle{})
+		s.glyphs[r] = g
+		return g, true
+	}
+
+	b := fixed.Rectangle26_6{
+		Min: segs[0].Args[0],
+		Max: segs[0].Args[0],
+	}
+	for _, seg := range segs {
+		n := 1
+		switch seg.Op {
+		case sfnt.SegmentOpQuadTo:
+			n = 2
+		case sfnt.SegmentOpCubeTo:
+			n = 3
+		}
+		for _, p := range seg.Args[:n] {
+			b = b.Union(fixed.Rectangle26_6{Min: p, Max: p.Add(fixed.Point26_6{X: 1, Y: 1})})
+		}
+	}
+	minX, minY := b.Min.X.Floor(), b.Min.Y.Floor()
+	maxX, maxY := b.Max.X.Ceil(), b.Max.Y.Ceil()
+	g.bounds = fixed.R(minX, minY, maxX, maxY)
+
+	w, h := maxX-minX, maxY-minY
+	pt := func(p fixed.Point26_6) (float32, float32) {
+		return float32(p.X)/64 - float32(minX), float32(p.Y)/64 - float32(minY)
+	}
+	z := vector.NewRasterizer(w, h)
+	for _, seg := range segs {
+		switch seg.Op {
+		case sfnt.SegmentOpMoveTo:
+			z.MoveTo(pt(seg.Args[0]))
+		case sfnt.SegmentOpLineTo:
+			z.LineTo(pt(seg.Args[0]))
+		case sfnt.SegmentOpQuadTo:
+			x0, y0 := pt(seg.Args[0])
+			x1, y1 := pt(seg.Args[1])
+			z.QuadTo(x0, y0, x1, y1)
+		case sfnt.SegmentOpCubeTo:
+			x0, y0 := pt(seg.Args[0])
+			x1, y1 := pt(seg.Args[1])
+			x2, y2 := pt(seg.Args[2])
+			z.CubeTo(x0, y0, x1, y1, x2, y2)
+		}
+	}
+	z.ClosePath()
+	g.mask = image.NewAlpha(image.Rect(0, 0, w, h))
+	z.Draw(g.mask, g.mask.Bounds(), image.Opaque, image.Point{})
+
+	s.glyphs[r] = g
+	return g, true
+}
+
+func (s *sfntFace) Glyph(dot fixed.Point26_6, r rune) (dr image.Rectangle, mask image.Image, maskp image.Point, advance fixed.Int26_6, ok bool) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	g, ok := s.glyph(r)
+	if !ok {
+		return image.Rectangle{}, nil, image.Point{}, 0, false
+	}
+	advance, ok = s.glyphAdvance(r)
+	if !ok {
+		return image.Rectangle{}, nil, image.Point{}, 0, false
+	}
+	d := image.Pt(dot.X.Floor(), dot.Y.Floor())
+	dr = image.Rect(g.bounds.Min.X.Floor(), g.bounds.Min.Y.Floor(), g.bounds.Max.X.Floor(), g.bounds.Max.Y.Floor()).Add(d)
+	return dr, g.mask, image.Point{}, advance, true
+}
+
+func (s *sfntFace) GlyphBounds(r rune) (bounds fixed.Rectangle26_6, advance fixed.Int26_6, ok bool) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	g, ok := s.glyph(r)
+	if !ok {
+		return fixed.Rectangle26_6{}, 0, false
+	}
+	advance, ok = s.glyphAdvance(r)
+	return g.bounds, advance, ok
+}
+
+func (s *sfntFace) glyphAdvance(r rune) (fixed.Int26_6, bool) {
+	idx, ok := s.index(r)
+	if !ok {
+		return 0, false
+	}
+	a, err := s.font.GlyphAdvance(&s.buf, idx, s.ppem, font.HintingNone)
+	if err != nil {
+		return 0, false
+	}
+	return a, true
+}
+
+func (s *sfntFace) GlyphAdvance(r rune) (advance fixed.Int26_6, ok bool) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.glyphAdvance(r)
+}
+
+func (s *sfntFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	idx0, ok := s.index(r0)
+	if !ok {
+		return 0
+	}
+	idx1, ok := s.index(r1)
+	if !ok {
+		return 0
+	}
+	k, err := s.font.Kern(&s.buf, idx0, idx1, s.ppem, font.HintingNone)
+	if err != nil {
+		return 0
+	}
+	return k
+}
+
+func (s *sfntFace) Metrics() font.Metrics {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	m, err := s.font.Metrics(&s.buf, s.ppem, font.HintingNone)
+	if err != nil {
+		return font.Metrics{}
+	}
+	return m
+}
+
+// fallbackFace is a font.Face that uses the first face that has the glyph.
+// The metrics are the last face's, that should be a bitmap font so that the layout doesn't change.
+type fallbackFace struct {
+	faces []font.Face
+}
+
+func (f *fallbackFace) faceFor(r rune) font.Face {
+	for _, face := range f.faces {
+		if _, ok := face.GlyphAdvance(r); ok {
+			return face
+		}
+	}
+	return f.faces[len(f.faces)-1]
+}
+
+func (f *fallbackFace) Close() error {
+	return nil
+}
+
+func (f *fallbackFace) Glyph(dot fixed.Point26_6, r rune) (dr image.Rectangle, mask image.Image, maskp image.Point, advance fixed.Int26_6, ok bool) {
+	return f.faceFor(r).Glyph(dot, r)
+}
+
+func (f *fallbackFace) GlyphBounds(r rune) (bounds fixed.Rectangle26_6, advance fixed.Int26_6, ok bool) {
+	return f.faceFor(r).GlyphBounds(r)
+}
+
+func (f *fallbackFace) GlyphAdvance(r rune) (advance fixed.Int26_6, ok bool) {
+	return f.faceFor(r).GlyphAdvance(r)
+}
+
+func (f *fallbackFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	face := f.faceFor(r0)
+	if face != f.faceFor(r1) {
+		return 0
+	}
+	return face.Kern(r0, r1)
+}
+
+func (f *fallbackFace) Metrics() font.Metrics {
+	return f.faces[len(f.faces)-1].Metrics()
+}
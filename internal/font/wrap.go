@@ -0,0 +1,218 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package font
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/lang"
+)
+
+const (
+	// noStartChars are characters that must not start a line (kinsoku rules).
+	noStartChars = "、。，．・：；？！゛゜ヽヾゝゞ々ー）］｝」』】〕〉》〙〗”’" +
+		"ぁぃぅぇぉっゃゅょゎゕゖァィゥェォッャュョヮヵヶ" +
+		",.!?:;)]}»…‥〜"
+
+	// noEndChars are characters that must not end a line (kinsoku rules).
+	noEndChars = "（［｛「『【〔〈《〘〖“‘([{«"
+
+	// unitRune is the rune to represent a unit like an inline icon at wrapping.
+	unitRune = '￼'
+)
+
+type wrapItem struct {
+	r       rune
+	advance fixed.Int26_6
+}
+
+type lineRange struct {
+	start int
+	end   int
+}
+
+// isBreakableChar reports whether lines can be broken before and after r.
+// Chinese and Japanese texts don't have spaces between words.
+func isBreakableChar(r rune) bool {
+	switch {
+	case r == unitRune:
+		return true
+	case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana):
+		return true
+	case 0x3000 <= r && r <= 0x303f:
+		// CJK Symbols and Punctuation
+		return true
+	case 0xff00 <= r && r <= 0xffef:
+		// Halfwidth and Fullwidth Forms
+		return true
+	}
+	return false
+}
+
+// canBreak reports whether a line can be broken between prev and next.
+// This is a simplified version of the Unicode line breaking algorithm (UAX #14) with kinsoku rules.
+func canBreak(prev, next rune) bool {
+	switch {
+	case unicode.IsSpace(next):
+		// Spaces hang at the end of the line.
+		return false
+	case unicode.IsSpace(prev):
+		return true
+	case strings.ContainsRune(noStartChars, next):
+		return false
+	case strings.ContainsRune(noEndChars, prev):
+		return false
+	case prev == '-' && unicode.IsLetter(next):
+		return true
+	case isBreakableChar(prev) || isBreakableChar(next):
+		return true
+	}
+	return false
+}
+
+// trimSpaceEnd returns the end of the range without the trailing spaces.
+func trimSpaceEnd(items []wrapItem, start, end int) int {
+	for end > start && unicode.IsSpace(items[end-1].r) {
+		end--
+	}
+	return end
+}
+
+// lineRanges splits the items into lines that fit in width.
+// The spaces at the line breaks are excluded from the ranges.
+func lineRanges(items []wrapItem, width fixed.Int26_6) []lineRange {
+	var ranges []lineRange
+	start := 0
+	for {
+		end := start
+		x := fixed.I(0)
+		for end < len(items) {
+			// Spaces can exceed the width as they are trimmed.
+			if x+items[end].advance > width && end > start && !unicode.IsSpace(items[end].r) {
+				break
+			}
+			x += items[end].advance
+			end++
+		}
+		if end == len(items) {
+			return append(ranges, lineRange{start, trimSpaceEnd(items, start, end)})
+		}
+
+		brk := end
+		for brk > start && !canBreak(items[brk-1].r, items[brk].r) {
+			brk--
+		}
+		if brk == start {
+			// There is no break opportunity like a long word. Break at the character.
+			brk = end
+		}
+		ranges = append(ranges, lineRange{start, trimSpaceEnd(items, start, brk)})
+
+		start = brk
+		for start < len(items) && unicode.IsSpace(items[start].r) {
+			start++
+		}
+		if start == len(items) {
+			return ranges
+		}
+	}
+}
+
+// WrapMonospaceForTesting splits str into lines that fit in width, assuming that every rune's advance is 1.
+func WrapMonospaceForTesting(str string, width int) []string {
+	rs := []rune(str)
+	items := make([]wrapItem, len(rs))
+	for i, r := range rs {
+		items[i] = wrapItem{
+			r:       r,
+			advance: fixed.I(1),
+		}
+	}
+	var lines []string
+	for _, r := range lineRanges(items, fixed.I(width)) {
+		lines = append(lines, string(rs[r.start:r.end]))
+	}
+	return lines
+}
+
+func runeItems(f font.Face, str string, scale float64) []wrapItem {
+	var items []wrapItem
+	prev := rune(-1)
+	for _, r := range str {
+		a, _ := f.GlyphAdvance(r)
+		if prev >= 0 {
+			a += f.Kern(prev, r)
+		}
+		items = append(items, wrapItem{
+			r:       r,
+			advance: fixed.Int26_6(float64(a) * scale),
+		})
+		prev = r
+	}
+	return items
+}
+
+// Wrap inserts line breaks into str so that each line fits in width.
+// width is in the same unit as MeasureSize.
+func Wrap(str string, width int) string {
+	if width <= 0 {
+		return str
+	}
+	f := face(1, lang.Get())
+	var lines []string
+	for _, l := range strings.Split(ToValidContent(str), "\n") {
+		rs := []rune(l)
+		for _, r := range lineRanges(runeItems(f, l, 1), fixed.I(width)) {
+			lines = append(lines, string(rs[r.start:r.end]))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// LineBreaks returns the positions where new lines should start so that each line of the spans fits in width.
+// width is in the same unit as MeasureSize.
+// The positions are counted in the same way as DrawRichText's RuneCount.
+func LineBreaks(spans []TextSpan, width int) []int {
+	if width <= 0 {
+		return nil
+	}
+	l := lang.Get()
+	var breaks []int
+	index := 0
+	for _, line := range splitSpanLines(spans) {
+		var items []wrapItem
+		for i := range line {
+			s := &line[i]
+			if s.isUnit() {
+				items = append(items, wrapItem{
+					r:       unitRune,
+					advance: fixed.Int26_6(spanWidth(l, s) * 64),
+				})
+				continue
+			}
+			items = append(items, runeItems(s.face(l), s.Text, s.scale())...)
+		}
+		for _, r := range lineRanges(items, fixed.I(width))[1:] {
+			breaks = append(breaks, index+r.start)
+		}
+		// The line break is counted as a rune.
+		index += len(items) + 1
+	}
+	return breaks
+}
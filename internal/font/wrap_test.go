@@ -0,0 +1,112 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package font_test
+
+import (
+	"reflect"
+	"testing"
+
+	. "github.com/hajimehoshi/rpgsnack-runtime/internal/font"
+)
+
+func TestWrap(t *testing.T) {
+	cases := []struct {
+		In    string
+		Width int
+		Want  []string
+	}{
+		{
+			In:    "hello world",
+			Width: 8,
+			Want:  []string{"hello", "world"},
+		},
+		{
+			In:    "hello   world",
+			Width: 8,
+			Want:  []string{"hello", "world"},
+		},
+		{
+			// Spaces at the end of a line can exceed the width.
+			In:    "abc   def",
+			Width: 3,
+			Want:  []string{"abc", "def"},
+		},
+		{
+			In:    "abc   ",
+			Width: 10,
+			Want:  []string{"abc"},
+		},
+		{
+			// A long word is broken at the character.
+			In:    "abcdefghij",
+			Width: 4,
+			Want:  []string{"abcd", "efgh", "ij"},
+		},
+		{
+			In:    "a abcdefghij",
+			Width: 4,
+			Want:  []string{"a", "abcd", "efgh", "ij"},
+		},
+		{
+			In:    "well-known",
+			Width: 6,
+			Want:  []string{"well-", "known"},
+		},
+		{
+			In:    "1-2",
+			Width: 2,
+			Want:  []string{"1-", "2"},
+		},
+		{
+			In:    "あいうえお",
+			Width: 2,
+			Want:  []string{"あい", "うえ", "お"},
+		},
+		{
+			// '。' must not start a line.
+			In:    "あいうえ。お",
+			Width: 4,
+			Want:  []string{"あいう", "え。お"},
+		},
+		{
+			// 'っ' must not start a line.
+			In:    "あいうっか",
+			Width: 3,
+			Want:  []string{"あい", "うっか"},
+		},
+		{
+			// '「' must not end a line.
+			In:    "あいう「えお」",
+			Width: 4,
+			Want:  []string{"あいう", "「えお」"},
+		},
+		{
+			In:    "abc (def)",
+			Width: 6,
+			Want:  []string{"abc", "(def)"},
+		},
+		{
+			In:    "",
+			Width: 4,
+			Want:  []string{""},
+		},
+	}
+	for _, c := range cases {
+		got := WrapMonospaceForTesting(c.In, c.Width)
+		if !reflect.DeepEqual(got, c.Want) {
+			t.Errorf("WrapMonospaceForTesting(%q, %d): got: %q, want: %q", c.In, c.Width, got, c.Want)
+		}
+	}
+}
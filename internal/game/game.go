@@ -27,6 +27,7 @@ import (
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/assets"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/audio"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/font"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/scene"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/sceneimpl"
 )
@@ -142,6 +143,9 @@ func (g *Game) update() error {
 			g.loadProgressCh = nil
 			da := d.LoadedData
 			assets.Set(da.Assets, da.AssetsMetadata)
+			if err := font.LoadFonts(da.Game); err != nil {
+				return err
+			}
 			g.sceneManager = scene.NewManager(g.width, g.height, g.requester, da.Game, da.Progress, da.Permanent, da.Purchases, sceneimpl.FadingCount)
 			g.sceneManager.SetLanguage(da.Language)
 			s, err := sceneimpl.NewInitialScene(g.sceneManager)
@@ -238,6 +242,10 @@ func (g *Game) RespondAsset(id int, success bool, data []byte) {
 	g.sceneManager.RespondAsset(id, success, data)
 }
 
+func (g *Game) RespondInputText(id int, success bool, text string) {
+	g.sceneManager.RespondInputText(id, success, text)
+}
+
 func (g *Game) SetPlatformData(key scene.PlatformDataKey, value string) {
 	args := setPlatformDataArgs{
 		key:   key,
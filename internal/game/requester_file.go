@@ -230,3 +230,11 @@ func (m *Requester) RequestAsset(requestID int, key string) {
 func (m *Requester) RequestMarkNewsRead(newsId int64) {
 	log.Printf("request mark news %d read", newsId)
 }
+
+func (m *Requester) RequestInputText(requestID int, title string, text string, maxLength int, layout string) {
+	log.Printf("request input text: title: %s, text: %s, max length: %d, layout: %s", title, text, maxLength, layout)
+	// There is no native keyboard. The on-screen keyboard is used instead.
+	go func() {
+		m.game.RespondInputText(requestID, false, "")
+	}()
+}
@@ -116,11 +116,14 @@ type Game struct {
 	autoSaveEnabled      bool
 	playerControlEnabled bool
 	inventoryVisible     bool
-	weatherType          data.WeatherType
+	weather              *weather.Weather
+	lighting             *Lighting
 	cleared              bool
 
-	lastPlayingBGMName   string
-	lastPlayingBGMVolume float64
+	lastPlayingBGMName     string
+	lastPlayingBGMVolume   float64
+	lastPlayingBGMPosition time.Duration
+	lastPlayingBGSs        []audio.BGS
 
 	backgrounds map[int]map[int]string
 	foregrounds map[int]map[int]string
@@ -134,11 +137,13 @@ type Game struct {
 	rand                         Rand
 	waitingRequestIDs            map[int]struct{}
 	prices                       map[string]string // TODO: We want to use https://godoc.org/golang.org/x/text/currency
-	weather                      *weather.Weather
+	pricesVersion                int
 	onShakeStartGameButton       func()
 	shouldShowCredits            bool
 	shouldShowCreditsCloseButton bool
 	minigame                     *Minigame
+	textInput                    *TextInput
+	numberInput                  *NumberInput
 }
 
 func generateDefaultRand() Rand {
@@ -223,8 +228,11 @@ func (g *Game) EncodeMsgpack(enc *msgpack.Encoder) error {
 	e.EncodeString("inventoryVisible")
 	e.EncodeBool(g.inventoryVisible)
 
-	e.EncodeString("weatherType")
-	e.EncodeString(string(g.weatherType))
+	e.EncodeString("weather")
+	e.EncodeInterface(g.weather)
+
+	e.EncodeString("lighting")
+	e.EncodeInterface(g.lighting)
 
 	e.EncodeString("cleared")
 	e.EncodeBool(g.cleared)
@@ -235,6 +243,23 @@ func (g *Game) EncodeMsgpack(enc *msgpack.Encoder) error {
 	e.EncodeString("lastPlayingBGMVolume")
 	e.EncodeFloat64(audio.PlayingBGMVolume())
 
+	e.EncodeString("lastPlayingBGMPosition")
+	e.EncodeInt(int(audio.PlayingBGMPosition() / time.Millisecond))
+
+	e.EncodeString("lastPlayingBGSs")
+	e.BeginArray()
+	for _, b := range audio.PlayingBGSs() {
+		e.BeginMap()
+		e.EncodeString("channel")
+		e.EncodeInt(b.Channel)
+		e.EncodeString("name")
+		e.EncodeString(b.Name)
+		e.EncodeString("volume")
+		e.EncodeFloat64(b.Volume)
+		e.EndMap()
+	}
+	e.EndArray()
+
 	e.EncodeString("playerSpeed")
 	e.EncodeInt(int(g.playerSpeed))
 
@@ -317,14 +342,47 @@ func (g *Game) DecodeMsgpack(dec *msgpack.Decoder) error {
 			g.playerControlEnabled = d.DecodeBool()
 		case "inventoryVisible":
 			g.inventoryVisible = d.DecodeBool()
+		case "weather":
+			if !d.SkipCodeIfNil() {
+				g.weather = &weather.Weather{}
+				d.DecodeInterface(g.weather)
+			}
+		case "lighting":
+			if !d.SkipCodeIfNil() {
+				g.lighting = &Lighting{}
+				d.DecodeInterface(g.lighting)
+			}
 		case "weatherType":
-			g.SetWeather(data.WeatherType(d.DecodeString()))
+			// Old save data has only the weather type.
+			g.SetWeather(data.WeatherType(d.DecodeString()), 1, 0, 0, "", 0)
 		case "cleared":
 			g.cleared = d.DecodeBool()
 		case "lastPlayingBGMName":
 			g.lastPlayingBGMName = d.DecodeString()
 		case "lastPlayingBGMVolume":
 			g.lastPlayingBGMVolume = d.DecodeFloat64()
+		case "lastPlayingBGMPosition":
+			g.lastPlayingBGMPosition = time.Duration(d.DecodeInt()) * time.Millisecond
+		case "lastPlayingBGSs":
+			if !d.SkipCodeIfNil() {
+				n := d.DecodeArrayLen()
+				g.lastPlayingBGSs = make([]audio.BGS, n)
+				for i := 0; i < n; i++ {
+					m := d.DecodeMapLen()
+					for j := 0; j < m; j++ {
+						switch k := d.DecodeString(); k {
+						case "channel":
+							g.lastPlayingBGSs[i].Channel = d.DecodeInt()
+						case "name":
+							g.lastPlayingBGSs[i].Name = d.DecodeString()
+						case "volume":
+							g.lastPlayingBGSs[i].Volume = d.DecodeFloat64()
+						default:
+							d.Skip()
+						}
+					}
+				}
+			}
 		case "playerSpeed":
 			g.playerSpeed = data.Speed(d.DecodeInt())
 			if g.playerSpeed == 0 {
@@ -406,26 +464,40 @@ func (m *messageSyntaxParser) ParseMessageSyntax(content string) string {
 	return m.game.parseMessageSyntax(m.sceneManager, content)
 }
 
+// Version returns a number that changes whenever the values referred by message commands might change.
+func (m *messageSyntaxParser) Version() int {
+	return m.game.variables.Version() + m.game.pricesVersion
+}
+
 func (g *Game) Update(sceneManager *scene.Manager) error {
 	g.items.SetDataItems(sceneManager.Game().Items)
 	if g.lastPlayingBGMName != "" {
-		audio.PlayBGM(g.lastPlayingBGMName, g.lastPlayingBGMVolume, 0)
+		audio.PlayBGMAt(g.lastPlayingBGMName, g.lastPlayingBGMVolume, 0, g.lastPlayingBGMPosition)
 		g.lastPlayingBGMName = ""
 		g.lastPlayingBGMVolume = 0
+		g.lastPlayingBGMPosition = 0
+	}
+	for _, b := range g.lastPlayingBGSs {
+		audio.PlayBGS(b.Channel, b.Name, b.Volume, 0)
 	}
+	g.lastPlayingBGSs = nil
 	for id := range g.waitingRequestIDs {
 		if sceneManager.ReceiveResultIfExists(id) != nil {
 			delete(g.waitingRequestIDs, id)
 		}
 	}
 	g.weather.Update()
+	if g.lighting != nil {
+		g.lighting.Update()
+	}
 	g.screen.Update()
 	playerY := 0
 	if g.currentMap.player != nil {
 		_, playerY = g.currentMap.player.DrawPosition()
 	}
 	g.windows.Update(playerY, &messageSyntaxParser{g, sceneManager}, sceneManager, g.createCharacterList())
-	g.pictures.Update()
+	g.updateDucking(sceneManager)
+	g.pictures.Update(&messageSyntaxParser{g, sceneManager}, sceneManager.Game())
 
 	if err := g.currentMap.Update(sceneManager, g); err != nil {
 		return err
@@ -437,6 +509,25 @@ func (g *Game) Clear() {
 	g.cleared = true
 }
 
+func (g *Game) updateDucking(sceneManager *scene.Manager) {
+	d := sceneManager.Game().System.Ducking
+	if d == nil {
+		audio.SetDucking(false, 1, 0, 0)
+		return
+	}
+	v := float64(d.Volume) / data.MaxVolume
+	audio.SetDucking(g.windows.IsBusy(0), v, d.AttackTime*6, d.ReleaseTime*6)
+}
+
+// PreloadRoomBGM preloads the BGM played automatically in the room of the current map.
+func (g *Game) PreloadRoomBGM(roomID int) {
+	r := g.currentMap.room(roomID)
+	if r == nil || !r.AutoBGM || r.BGM.Name == "" {
+		return
+	}
+	audio.PreloadBGM(r.BGM.Name)
+}
+
 func (g *Game) SetBGM(bgm data.BGM) {
 	if bgm.Name == "" {
 		audio.StopBGM(0)
@@ -575,6 +666,35 @@ func (g *Game) parseMessageSyntax(sceneManager *scene.Manager, str string) strin
 				return fmt.Sprintf("(error:%v)", part)
 			}
 			return fmt.Sprintf("%d", g.variables.VariableValue(id))
+		case "w":
+			id, err := strconv.Atoi(args)
+			if err != nil {
+				return fmt.Sprintf("(error:%v)", part)
+			}
+			return g.variables.StringVariableValue(id)
+		case "g":
+			// An item ID is converted to the item's icon. Other arguments are icon names.
+			itemID := 0
+			if m := reMessageVariable.FindStringSubmatch(args); m != nil {
+				varID, err := strconv.Atoi(m[1])
+				if err != nil {
+					return fmt.Sprintf("(error:%v)", part)
+				}
+				itemID = int(g.VariableValue(varID))
+			} else {
+				id, err := strconv.Atoi(args)
+				if err != nil {
+					return part
+				}
+				itemID = id
+			}
+			if i := g.items.Item(itemID); i != nil {
+				return `\g[` + i.Icon + `]`
+			}
+			return part
+		case "c", "s", "e", "r":
+			// Markup tags are handled at the windows.
+			return part
 		case "t":
 			if m1 := reMessageTable.FindStringSubmatch(args); m1 != nil {
 				tableName := m1[1]
@@ -731,6 +851,52 @@ func (g *Game) DrawWeather(screen *ebiten.Image) {
 	g.weather.Draw(screen)
 }
 
+func (g *Game) DrawLighting(screen *ebiten.Image, offsetX, offsetY int) {
+	if g.lighting == nil {
+		return
+	}
+	g.lighting.Draw(screen, offsetX, offsetY, g)
+}
+
+func (g *Game) ensureLighting() *Lighting {
+	if g.lighting == nil {
+		g.lighting = &Lighting{}
+	}
+	return g.lighting
+}
+
+func (g *Game) SetDarkness(darkness float64, darknessColor color.RGBA, count int) {
+	g.ensureLighting().setDarkness(darkness, darknessColor, count)
+}
+
+func (g *Game) IsChangingDarkness() bool {
+	if g.lighting == nil {
+		return false
+	}
+	return g.lighting.isChangingDarkness()
+}
+
+func (g *Game) SetLight(id int, mapID, roomID int, targetType data.LightTargetType, eventID int, x, y int, radius float64, lightColor color.RGBA, flicker float64, count int) {
+	l := &light{
+		mapID:      mapID,
+		roomID:     roomID,
+		targetType: targetType,
+		eventID:    eventID,
+		x:          x,
+		y:          y,
+		color:      lightColor,
+		flicker:    flicker,
+	}
+	g.ensureLighting().setLight(id, l, radius, count)
+}
+
+func (g *Game) EraseLight(id int) {
+	if g.lighting == nil {
+		return
+	}
+	g.lighting.eraseLight(id)
+}
+
 func (g *Game) ApplyTintColor(c *ebiten.ColorM) {
 	g.screen.ApplyTintColor(c)
 }
@@ -759,7 +925,7 @@ func (g *Game) createCharacterList() []*character.Character {
 }
 
 func (g *Game) DrawPictures(screen *ebiten.Image, offsetX, offsetY int, priority data.PicturePriorityType) {
-	g.pictures.Draw(screen, offsetX, offsetY, priority)
+	g.pictures.Draw(screen, offsetX, offsetY, priority, g.createCharacterList())
 }
 
 func (g *Game) Character(mapID, roomID, eventID int) *character.Character {
@@ -789,6 +955,7 @@ func (g *Game) price(productID string) string {
 
 func (g *Game) SetPrices(p map[string]string) {
 	g.prices = p
+	g.pricesVersion++
 }
 
 func (g *Game) CanWindowProceed(interpreterID consts.InterpreterID) bool {
@@ -815,21 +982,31 @@ func (g *Game) ChosenWindowIndex() int {
 	return g.windows.ChosenIndex()
 }
 
-func (g *Game) ShowBalloon(sceneManager *scene.Manager, interpreterID consts.InterpreterID, mapID, roomID, eventID int, contentID data.UUID, balloonType data.BalloonType, messageStyle *data.MessageStyle) bool {
+func (g *Game) ChoicesCanceled() bool {
+	return g.windows.ChosenIndex() == window.ChoiceIndexCanceled
+}
+
+func (g *Game) ShowBalloon(sceneManager *scene.Manager, interpreterID consts.InterpreterID, mapID, roomID, eventID int, contentID data.UUID, balloonType data.BalloonType, messageStyle *data.MessageStyle, voice string, waitVoice bool, speaker *data.MessageSpeaker) bool {
 	ch := g.Character(mapID, roomID, eventID)
 	if ch == nil {
 		return false
 	}
 
-	g.windows.ShowBalloon(contentID, &messageSyntaxParser{g, sceneManager}, sceneManager.Game(), balloonType, eventID, interpreterID, messageStyle)
+	g.windows.ShowBalloon(contentID, &messageSyntaxParser{g, sceneManager}, sceneManager.Game(), balloonType, eventID, interpreterID, messageStyle, voice, waitVoice, speaker)
 	return true
 }
 
-func (g *Game) ShowMessage(sceneManager *scene.Manager, interpreterID consts.InterpreterID, eventID int, contentID data.UUID, background data.MessageBackground, positionType data.MessagePositionType, textAlign data.TextAlign, messageStyle *data.MessageStyle) {
-	g.windows.ShowMessage(contentID, &messageSyntaxParser{g, sceneManager}, sceneManager.Game(), eventID, background, positionType, textAlign, interpreterID, messageStyle)
+func (g *Game) ShowMessage(sceneManager *scene.Manager, interpreterID consts.InterpreterID, eventID int, contentID data.UUID, background data.MessageBackground, positionType data.MessagePositionType, textAlign data.TextAlign, messageStyle *data.MessageStyle, voice string, waitVoice bool, speaker *data.MessageSpeaker) {
+	g.windows.ShowMessage(contentID, &messageSyntaxParser{g, sceneManager}, sceneManager.Game(), eventID, background, positionType, textAlign, interpreterID, messageStyle, voice, waitVoice, speaker)
 }
 
-func (g *Game) ShowChoices(sceneManager *scene.Manager, interpreterID consts.InterpreterID, eventID int, choiceIDs []data.UUID, conditions []*data.ChoiceCondition) {
+func (g *Game) ShowChoices(sceneManager *scene.Manager, interpreterID consts.InterpreterID, eventID int, args *data.CommandArgsShowChoices) {
+	choiceIDs := args.ChoiceIDs
+	conditions := args.Conditions
+
+	// The indices in the arguments are converted into the indices of the visible choices.
+	defaultIndex := -1
+	timeoutIndex := window.ChoiceIndexCanceled
 	choices := []*window.Choice{}
 	for i, id := range choiceIDs {
 		choice := &window.Choice{ID: id, Checked: false}
@@ -851,13 +1028,49 @@ func (g *Game) ShowChoices(sceneManager *scene.Manager, interpreterID consts.Int
 				}
 				choice.Checked = m
 			}
+			if i < len(conditions) {
+				if conditions[i].Enabled != nil {
+					m, err := g.MeetsCondition(conditions[i].Enabled, eventID)
+					if err != nil {
+						panic(err)
+					}
+					choice.Disabled = !m
+					choice.DisabledReasonID = conditions[i].DisabledReason
+				}
+				choice.Icon = conditions[i].Icon
+			}
+			if i == args.DefaultIndex {
+				defaultIndex = len(choices)
+			}
+			if i == args.TimeoutIndex && !choice.Disabled {
+				timeoutIndex = len(choices)
+			}
 			choices = append(choices, choice)
 		}
 	}
-	g.windows.ShowChoices(&messageSyntaxParser{g, sceneManager}, sceneManager.Game(), choices, interpreterID)
+
+	// When the choice for the timeout is invisible or disabled, the choices are canceled if possible.
+	// Otherwise, the first enabled choice is chosen. When all the choices are disabled, the choices are canceled anyway.
+	if timeoutIndex == window.ChoiceIndexCanceled && !args.Cancelable {
+		for i, c := range choices {
+			if !c.Disabled {
+				timeoutIndex = i
+				break
+			}
+		}
+	}
+	g.windows.ShowChoices(&messageSyntaxParser{g, sceneManager}, sceneManager.Game(), choices, defaultIndex, args.Timeout*6, timeoutIndex, args.Cancelable, interpreterID)
+}
+
+// CancelChoices cancels the current choices if the choices are cancelable.
+func (g *Game) CancelChoices() bool {
+	return g.windows.CancelChoices()
 }
 
 func (g *Game) RealChoiceIndex(sceneManager *scene.Manager, index int, eventID int, conditions []*data.ChoiceCondition) int {
+	if index == window.ChoiceIndexCanceled {
+		return -1
+	}
 	if len(conditions) == 0 {
 		return index
 	}
@@ -919,16 +1132,14 @@ func (g *Game) SetPlayerDir(dir data.Dir) {
 	g.currentMap.player.SetDir(dir)
 }
 
-func (g *Game) SetWeather(weatherType data.WeatherType) {
-	if g.weatherType == weatherType {
-		return
-	}
-	g.weatherType = weatherType
-	if weatherType == data.WeatherTypeNone {
-		g.weather = nil
-		return
+func (g *Game) SetWeather(weatherType data.WeatherType, intensity float64, windDirection, windStrength float64, imageName string, count int) {
+	if g.weather == nil {
+		if weatherType == data.WeatherTypeNone {
+			return
+		}
+		g.weather = weather.New()
 	}
-	g.weather = weather.New(weatherType)
+	g.weather.Set(weatherType, intensity, windDirection, windStrength, imageName, count)
 }
 
 func (g *Game) TransferPlayerImmediately(roomID, x, y int, interpreter InterpreterInterface) {
@@ -994,6 +1205,9 @@ func (g *Game) InterfaceToTableValue(sceneManager *scene.Manager, v interface{})
 	if a.Type == data.ValueTypeVariable {
 		id = int(g.VariableValue(id))
 	}
+	if v, ok := g.variables.TableValue(a.Name, id, a.Attr); ok {
+		return v
+	}
 	return sceneManager.Game().GetTableValue(a.Name, id, a.Attr)
 }
 
@@ -1006,6 +1220,9 @@ func (g *Game) GetItemValueString(sceneManager *scene.Manager, itemID int) strin
 }
 
 func (g *Game) GetTableValueString(sceneManager *scene.Manager, tableName string, recordID int, attrName string) string {
+	if v, ok := g.variables.TableValue(tableName, recordID, attrName); ok {
+		return v
+	}
 	t := sceneManager.Game().GetTableValueType(tableName, attrName)
 	v := sceneManager.Game().GetTableValue(tableName, recordID, attrName)
 	r := ""
@@ -1288,6 +1505,10 @@ func (g *Game) ShakeStartGameButton() {
 	}
 }
 
+func (g *Game) MessageHistory() []*window.HistoryEntry {
+	return g.windows.History()
+}
+
 func (g *Game) ShouldShowCredits() bool {
 	return g.shouldShowCredits
 }
@@ -1306,7 +1527,7 @@ func (g *Game) ShowedCredits() {
 }
 
 func (g *Game) touchingPictureID(x, y int) int {
-	return g.pictures.TouchingPictureID(x, y)
+	return g.pictures.TouchingPictureID(x, y, g.createCharacterList())
 }
 
 // UpdatePictureTouch updates the picture touch states
@@ -33,6 +33,7 @@ import (
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/easymsgpack"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/lang"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/movecharacterstate"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/picture"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/scene"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/variables"
 )
@@ -428,7 +429,7 @@ func (i *Interpreter) doOneCommand(sceneManager *scene.Manager, gameState *Game)
 				id = i.eventID
 			}
 			messageStyle := i.findMessageStyle(sceneManager, args.MessageStyleID)
-			if gameState.ShowBalloon(sceneManager, i.id, i.mapID, i.roomID, id, args.ContentID, args.BalloonType, messageStyle) {
+			if gameState.ShowBalloon(sceneManager, i.id, i.mapID, i.roomID, id, args.ContentID, args.BalloonType, messageStyle, args.Voice, args.WaitVoice, args.Speaker) {
 				i.waitingCommand = true
 				return false, nil
 			}
@@ -457,7 +458,7 @@ func (i *Interpreter) doOneCommand(sceneManager *scene.Manager, gameState *Game)
 			}
 
 			messageStyle := i.findMessageStyle(sceneManager, args.MessageStyleID)
-			gameState.ShowMessage(sceneManager, i.id, id, args.ContentID, args.Background, args.PositionType, args.TextAlign, messageStyle)
+			gameState.ShowMessage(sceneManager, i.id, id, args.ContentID, args.Background, args.PositionType, args.TextAlign, messageStyle, args.Voice, args.WaitVoice, args.Speaker)
 			i.waitingCommand = true
 			return false, nil
 		}
@@ -501,7 +502,7 @@ func (i *Interpreter) doOneCommand(sceneManager *scene.Manager, gameState *Game)
 			if gameState.windows.IsBusyWithChoosing() {
 				return false, nil
 			}
-			gameState.ShowChoices(sceneManager, i.id, i.eventID, c.Args.(*data.CommandArgsShowChoices).ChoiceIDs, c.Args.(*data.CommandArgsShowChoices).Conditions)
+			gameState.ShowChoices(sceneManager, i.id, i.eventID, c.Args.(*data.CommandArgsShowChoices))
 			i.waitingCommand = true
 			return false, nil
 		}
@@ -512,7 +513,18 @@ func (i *Interpreter) doOneCommand(sceneManager *scene.Manager, gameState *Game)
 			return false, nil
 		}
 
-		idx := gameState.RealChoiceIndex(sceneManager, gameState.ChosenWindowIndex(), i.eventID, c.Args.(*data.CommandArgsShowChoices).Conditions)
+		args := c.Args.(*data.CommandArgsShowChoices)
+		if gameState.ChoicesCanceled() {
+			// The branch for canceling is next to the choices' branches.
+			if n := len(args.ChoiceIDs); len(c.Branches) > n {
+				i.commandIterator.Choose(n)
+			} else {
+				i.commandIterator.Advance()
+			}
+			i.waitingCommand = false
+			return false, nil
+		}
+		idx := gameState.RealChoiceIndex(sceneManager, gameState.ChosenWindowIndex(), i.eventID, args.Conditions)
 		if idx >= 0 {
 			i.commandIterator.Choose(idx)
 		} else {
@@ -592,6 +604,12 @@ func (i *Interpreter) doOneCommand(sceneManager *scene.Manager, gameState *Game)
 		}
 
 		if !i.waitingCommand {
+			roomID := args.RoomID
+			if args.ValueType == data.ValueTypeVariable {
+				roomID = int(gameState.VariableValue(roomID))
+			}
+			// Decode the next room's BGM while the screen is fading out.
+			gameState.PreloadRoomBGM(roomID)
 			if args.Transition == data.TransferTransitionTypeWhite {
 				gameState.SetFadeColor(color.White)
 			} else {
@@ -699,10 +717,82 @@ func (i *Interpreter) doOneCommand(sceneManager *scene.Manager, gameState *Game)
 		}
 		i.waitingCommand = false
 		i.commandIterator.Advance()
+	case data.CommandNameSetDarkness:
+		if !i.waitingCommand {
+			args := c.Args.(*data.CommandArgsSetDarkness)
+			darkness := float64(args.Darkness) / 255
+			clr := color.RGBA{uint8(args.Red), uint8(args.Green), uint8(args.Blue), 0xff}
+			gameState.SetDarkness(darkness, clr, args.Time*6)
+			if !args.Wait {
+				i.commandIterator.Advance()
+				return true, nil
+			}
+			i.waitingCommand = args.Wait
+		}
+		if gameState.IsChangingDarkness() {
+			return false, nil
+		}
+		i.waitingCommand = false
+		i.commandIterator.Advance()
+	case data.CommandNameSetLight:
+		args := c.Args.(*data.CommandArgsSetLight)
+		id := args.ID
+		if args.IDValueType == data.ValueTypeVariable {
+			id = int(gameState.VariableValue(id))
+		}
+		x := args.X
+		y := args.Y
+		if args.PosValueType == data.ValueTypeVariable {
+			x = int(gameState.VariableValue(x))
+			y = int(gameState.VariableValue(y))
+		}
+		eventID := args.EventID
+		if eventID == 0 {
+			eventID = i.eventID
+		}
+		clr := color.RGBA{uint8(args.Red), uint8(args.Green), uint8(args.Blue), 0xff}
+		gameState.SetLight(id, i.mapID, i.roomID, args.TargetType, eventID, x, y, float64(args.Radius), clr, float64(args.Flicker)/100, args.Time*6)
+		i.commandIterator.Advance()
+	case data.CommandNameEraseLight:
+		args := c.Args.(*data.CommandArgsEraseLight)
+		id := args.ID
+		if args.IDValueType == data.ValueTypeVariable {
+			id = int(gameState.VariableValue(id))
+		}
+		gameState.EraseLight(id)
+		i.commandIterator.Advance()
 	case data.CommandNamePlaySE:
 		args := c.Args.(*data.CommandArgsPlaySE)
-		v := float64(args.Volume) / data.MaxVolume
-		audio.PlaySE(args.Name, v)
+		h := audio.PlaySEWithOptions(args.Name, &audio.SEOptions{
+			Volume:   float64(args.Volume) / data.MaxVolume,
+			Pitch:    float64(args.Pitch) / 100,
+			Pan:      float64(args.Pan) / 100,
+			Priority: args.Priority,
+		})
+		if args.HandleVariableID > 0 {
+			gameState.SetVariableValue(args.HandleVariableID, int64(h))
+		}
+		i.commandIterator.Advance()
+	case data.CommandNamePreloadAudio:
+		args := c.Args.(*data.CommandArgsPreloadAudio)
+		for _, name := range args.Names {
+			switch args.Type {
+			case data.AudioTypeBGM:
+				audio.PreloadBGM(name)
+			case data.AudioTypeBGS:
+				audio.PreloadBGS(name)
+			case data.AudioTypeSE:
+				audio.PreloadSE(name)
+			}
+		}
+		i.commandIterator.Advance()
+	case data.CommandNameStopSE:
+		args := c.Args.(*data.CommandArgsStopSE)
+		if args.Name != "" {
+			audio.StopSEByName(args.Name)
+		} else if args.HandleVariableID > 0 {
+			audio.StopSE(int(gameState.VariableValue(args.HandleVariableID)))
+		}
 		i.commandIterator.Advance()
 	case data.CommandNamePlayBGM:
 		args := c.Args.(*data.CommandArgsPlayBGM)
@@ -710,12 +800,41 @@ func (i *Interpreter) doOneCommand(sceneManager *scene.Manager, gameState *Game)
 
 		name := fileValue(sceneManager, gameState, args.NameValueType, args.Name)
 
-		audio.PlayBGM(name, v, args.FadeTime*6)
+		if args.Resume {
+			audio.PlayBGMFromSavedPosition(name, v, args.FadeTime*6)
+		} else {
+			audio.PlayBGM(name, v, args.FadeTime*6)
+		}
 		i.commandIterator.Advance()
 	case data.CommandNameStopBGM:
 		args := c.Args.(*data.CommandArgsStopBGM)
 		audio.StopBGM(args.FadeTime * 6)
 		i.commandIterator.Advance()
+	case data.CommandNamePlayBGS:
+		args := c.Args.(*data.CommandArgsPlayBGS)
+		v := float64(args.Volume) / data.MaxVolume
+		audio.PlayBGS(args.Channel, args.Name, v, args.FadeTime*6)
+		i.commandIterator.Advance()
+	case data.CommandNameStopBGS:
+		args := c.Args.(*data.CommandArgsStopBGS)
+		audio.StopBGS(args.Channel, args.FadeTime*6)
+		i.commandIterator.Advance()
+	case data.CommandNameFadeBGS:
+		args := c.Args.(*data.CommandArgsFadeBGS)
+		if !i.waitingCommand {
+			v := float64(args.Volume) / data.MaxVolume
+			audio.FadeBGS(args.Channel, v, args.Time*6)
+			if !args.Wait {
+				i.commandIterator.Advance()
+				return true, nil
+			}
+			i.waitingCommand = true
+		}
+		if audio.IsFadingBGS(args.Channel) {
+			return false, nil
+		}
+		i.waitingCommand = false
+		i.commandIterator.Advance()
 	case data.CommandNameSave:
 		// Proceed the command iterator before saving so that the game resumes from the next command.
 		i.commandIterator.Advance()
@@ -739,7 +858,7 @@ func (i *Interpreter) doOneCommand(sceneManager *scene.Manager, gameState *Game)
 		i.commandIterator.Advance()
 	case data.CommandNameWeather:
 		args := c.Args.(*data.CommandArgsWeather)
-		gameState.SetWeather(args.Type)
+		gameState.SetWeather(args.Type, float64(args.Intensity)/100, float64(args.WindDirection)*math.Pi/180, float64(args.WindStrength)/100, args.Image, args.Time*6)
 		i.commandIterator.Advance()
 	case data.CommandNameGotoTitle:
 		args := c.Args.(*data.CommandArgsGotoTitle)
@@ -871,6 +990,70 @@ func (i *Interpreter) doOneCommand(sceneManager *scene.Manager, gameState *Game)
 		i.waitingCommand = false
 		return false, nil
 
+	case data.CommandNameInputText:
+		args := c.Args.(*data.CommandArgsInputText)
+		// The text input is not dumped. Show the window again when the game is loaded during the input.
+		if !i.waitingCommand || gameState.TextInput() == nil {
+			if err := gameState.ShowTextInput(sceneManager, args); err != nil {
+				return false, err
+			}
+			i.waitingCommand = true
+		}
+
+		t := gameState.TextInput()
+		if t.Active() {
+			return false, nil
+		}
+		gameState.HideTextInput()
+		i.waitingCommand = false
+
+		if t.Canceled() {
+			if len(c.Branches) >= 2 {
+				i.commandIterator.Choose(1)
+			} else {
+				i.commandIterator.Advance()
+			}
+			return false, nil
+		}
+		gameState.setInputTextValue(args, t.Value())
+		if len(c.Branches) >= 1 {
+			i.commandIterator.Choose(0)
+		} else {
+			i.commandIterator.Advance()
+		}
+		return false, nil
+
+	case data.CommandNameInputNumber:
+		args := c.Args.(*data.CommandArgsInputNumber)
+		// The number input is not dumped. Show the window again when the game is loaded during the input.
+		if !i.waitingCommand || gameState.NumberInput() == nil {
+			gameState.ShowNumberInput(sceneManager, args)
+			i.waitingCommand = true
+		}
+
+		n := gameState.NumberInput()
+		if n.Active() {
+			return false, nil
+		}
+		gameState.HideNumberInput()
+		i.waitingCommand = false
+
+		if n.Canceled() {
+			if len(c.Branches) >= 2 {
+				i.commandIterator.Choose(1)
+			} else {
+				i.commandIterator.Advance()
+			}
+			return false, nil
+		}
+		gameState.SetVariableValue(args.ID, int64(n.Value()))
+		if len(c.Branches) >= 1 {
+			i.commandIterator.Choose(0)
+		} else {
+			i.commandIterator.Advance()
+		}
+		return false, nil
+
 	case data.CommandNameVibrate:
 		args := c.Args.(*data.CommandArgsVibrate)
 		if sceneManager.VibrationEnabled() {
@@ -1133,7 +1316,34 @@ func (i *Interpreter) doOneCommand(sceneManager *scene.Manager, gameState *Game)
 		scaleY := float64(args.ScaleY) / 100
 		angle := float64(args.Angle) * math.Pi / 180
 		opacity := float64(args.Opacity) / 255
-		gameState.pictures.Add(id, args.Image, x, y, scaleX, scaleY, angle, opacity, args.OriginX, args.OriginY, args.BlendType, args.Priority, args.Touchable)
+		gameState.pictures.Add(id, args.Image, x, y, scaleX, scaleY, angle, opacity, args.OriginX, args.OriginY, args.BlendType, args.Priority, args.Touchable, args.TouchAlphaThreshold)
+		i.commandIterator.Advance()
+
+	case data.CommandNameShowTextPicture:
+		args := c.Args.(*data.CommandArgsShowTextPicture)
+		x := args.X
+		y := args.Y
+		id := args.ID
+		if args.IDValueType == data.ValueTypeVariable {
+			id = int(gameState.VariableValue(id))
+		}
+		if args.PosValueType == data.ValueTypeVariable {
+			x = int(gameState.VariableValue(x))
+			y = int(gameState.VariableValue(y))
+		}
+		scaleX := float64(args.ScaleX) / 100
+		scaleY := float64(args.ScaleY) / 100
+		angle := float64(args.Angle) * math.Pi / 180
+		opacity := float64(args.Opacity) / 255
+		textOptions := &picture.TextOptions{
+			ContentID: args.ContentID,
+			Text:      args.Text,
+			Scale:     float64(args.FontScale) / 100,
+			Color:     args.Color,
+			TextAlign: args.TextAlign,
+			WrapWidth: args.WrapWidth,
+		}
+		gameState.pictures.AddText(id, textOptions, &messageSyntaxParser{gameState, sceneManager}, sceneManager.Game(), x, y, scaleX, scaleY, angle, opacity, args.OriginX, args.OriginY, args.BlendType, args.Priority, args.Touchable)
 		i.commandIterator.Advance()
 
 	case data.CommandNameErasePicture:
@@ -1326,6 +1536,27 @@ func (i *Interpreter) doOneCommand(sceneManager *scene.Manager, gameState *Game)
 		gameState.pictures.ChangeImage(id, image)
 		i.commandIterator.Advance()
 
+	case data.CommandNameAttachPicture:
+		args := c.Args.(*data.CommandArgsAttachPicture)
+
+		id := args.ID
+		if args.IDValueType == data.ValueTypeVariable {
+			id = int(gameState.VariableValue(id))
+		}
+
+		parentID := args.ParentID
+		if args.ParentIDValueType == data.ValueTypeVariable {
+			parentID = int(gameState.VariableValue(parentID))
+		}
+		if args.ParentType == data.PictureParentTypeCharacter && parentID == 0 {
+			parentID = i.eventID
+		}
+
+		if err := gameState.pictures.Attach(id, args.ParentType, parentID); err != nil {
+			return false, err
+		}
+		i.commandIterator.Advance()
+
 	case data.CommandNameChangeBackground:
 		args := c.Args.(*data.CommandArgsChangeBackground)
 
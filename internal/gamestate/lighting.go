@@ -0,0 +1,402 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gamestate
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/vmihailenco/msgpack"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/consts"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/easymsgpack"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/interpolation"
+)
+
+const lightImageSize = 64
+
+// lightImage is a radial gradient image used for both cutting the darkness and glowing.
+var lightImage *ebiten.Image
+
+func init() {
+	const size = lightImageSize
+	pix := make([]byte, 4*size*size)
+	for j := 0; j < size; j++ {
+		for i := 0; i < size; i++ {
+			dx := (float64(i)+0.5)/size*2 - 1
+			dy := (float64(j)+0.5)/size*2 - 1
+			d := math.Min(1, math.Sqrt(dx*dx+dy*dy))
+			// Smoothstep makes the edge of the light soft.
+			a := byte(0xff * (1 - d*d*(3-2*d)))
+			idx := 4 * (j*size + i)
+			pix[idx] = a
+			pix[idx+1] = a
+			pix[idx+2] = a
+			pix[idx+3] = a
+		}
+	}
+	lightImage, _ = ebiten.NewImage(size, size, ebiten.FilterDefault)
+	lightImage.ReplacePixels(pix)
+}
+
+type light struct {
+	mapID      int
+	roomID     int
+	targetType data.LightTargetType
+	eventID    int
+	x          int
+	y          int
+	radius     *interpolation.I
+	color      color.RGBA
+	flicker    float64
+}
+
+func (l *light) EncodeMsgpack(enc *msgpack.Encoder) error {
+	e := easymsgpack.NewEncoder(enc)
+	e.BeginMap()
+
+	e.EncodeString("mapId")
+	e.EncodeInt(l.mapID)
+
+	e.EncodeString("roomId")
+	e.EncodeInt(l.roomID)
+
+	e.EncodeString("targetType")
+	e.EncodeString(string(l.targetType))
+
+	e.EncodeString("eventId")
+	e.EncodeInt(l.eventID)
+
+	e.EncodeString("x")
+	e.EncodeInt(l.x)
+
+	e.EncodeString("y")
+	e.EncodeInt(l.y)
+
+	e.EncodeString("radius")
+	e.EncodeInterface(l.radius)
+
+	e.EncodeString("color")
+	e.BeginArray()
+	e.EncodeInt(int(l.color.R))
+	e.EncodeInt(int(l.color.G))
+	e.EncodeInt(int(l.color.B))
+	e.EncodeInt(int(l.color.A))
+	e.EndArray()
+
+	e.EncodeString("flicker")
+	e.EncodeFloat64(l.flicker)
+
+	e.EndMap()
+	return e.Flush()
+}
+
+func (l *light) DecodeMsgpack(dec *msgpack.Decoder) error {
+	d := easymsgpack.NewDecoder(dec)
+	n := d.DecodeMapLen()
+	for i := 0; i < n; i++ {
+		switch k := d.DecodeString(); k {
+		case "mapId":
+			l.mapID = d.DecodeInt()
+		case "roomId":
+			l.roomID = d.DecodeInt()
+		case "targetType":
+			l.targetType = data.LightTargetType(d.DecodeString())
+		case "eventId":
+			l.eventID = d.DecodeInt()
+		case "x":
+			l.x = d.DecodeInt()
+		case "y":
+			l.y = d.DecodeInt()
+		case "radius":
+			l.radius = &interpolation.I{}
+			d.DecodeInterface(l.radius)
+		case "color":
+			n := d.DecodeArrayLen()
+			if n != 4 {
+				for i := 0; i < n; i++ {
+					d.Skip()
+				}
+				break
+			}
+			l.color.R = uint8(d.DecodeInt())
+			l.color.G = uint8(d.DecodeInt())
+			l.color.B = uint8(d.DecodeInt())
+			l.color.A = uint8(d.DecodeInt())
+		case "flicker":
+			l.flicker = d.DecodeFloat64()
+		default:
+			if err := d.Error(); err != nil {
+				return err
+			}
+			return fmt.Errorf("gamestate: light.DecodeMsgpack failed: unknown key: %s", k)
+		}
+	}
+	if err := d.Error(); err != nil {
+		return fmt.Errorf("gamestate: light.DecodeMsgpack failed: %v", err)
+	}
+	return nil
+}
+
+// position returns the center of the light in the map coordinate.
+// position returns false when the light is not in the current room.
+func (l *light) position(game *Game) (float64, float64, bool) {
+	switch l.targetType {
+	case data.LightTargetTypeCharacter:
+		c := game.Character(l.mapID, l.roomID, l.eventID)
+		if c == nil || c.Erased() || !c.Visible() {
+			return 0, 0, false
+		}
+		x, y := c.DrawPosition()
+		w, h := c.Size()
+		return float64(x) + float64(w)/2, float64(y) + float64(h)/2, true
+	case data.LightTargetTypeTile:
+		if game.currentMap.mapID != l.mapID || game.currentMap.roomID != l.roomID {
+			return 0, 0, false
+		}
+		x := l.x*consts.TileSize + consts.TileSize/2
+		y := l.y*consts.TileSize + consts.TileSize/2
+		return float64(x), float64(y), true
+	}
+	return 0, 0, false
+}
+
+type Lighting struct {
+	darkness      *interpolation.I
+	darknessColor color.RGBA
+	lights        map[int]*light
+
+	// Not dumped
+	frame   int
+	overlay *ebiten.Image
+}
+
+func (l *Lighting) EncodeMsgpack(enc *msgpack.Encoder) error {
+	e := easymsgpack.NewEncoder(enc)
+	e.BeginMap()
+
+	e.EncodeString("darkness")
+	e.EncodeInterface(l.darkness)
+
+	e.EncodeString("darknessColor")
+	e.BeginArray()
+	e.EncodeInt(int(l.darknessColor.R))
+	e.EncodeInt(int(l.darknessColor.G))
+	e.EncodeInt(int(l.darknessColor.B))
+	e.EncodeInt(int(l.darknessColor.A))
+	e.EndArray()
+
+	e.EncodeString("lights")
+	e.BeginMap()
+	for id, li := range l.lights {
+		e.EncodeInt(id)
+		e.EncodeInterface(li)
+	}
+	e.EndMap()
+
+	e.EndMap()
+	return e.Flush()
+}
+
+func (l *Lighting) DecodeMsgpack(dec *msgpack.Decoder) error {
+	d := easymsgpack.NewDecoder(dec)
+	n := d.DecodeMapLen()
+	for i := 0; i < n; i++ {
+		switch k := d.DecodeString(); k {
+		case "darkness":
+			l.darkness = &interpolation.I{}
+			d.DecodeInterface(l.darkness)
+		case "darknessColor":
+			n := d.DecodeArrayLen()
+			if n != 4 {
+				for i := 0; i < n; i++ {
+					d.Skip()
+				}
+				break
+			}
+			l.darknessColor.R = uint8(d.DecodeInt())
+			l.darknessColor.G = uint8(d.DecodeInt())
+			l.darknessColor.B = uint8(d.DecodeInt())
+			l.darknessColor.A = uint8(d.DecodeInt())
+		case "lights":
+			if !d.SkipCodeIfNil() {
+				n := d.DecodeMapLen()
+				l.lights = map[int]*light{}
+				for i := 0; i < n; i++ {
+					id := d.DecodeInt()
+					li := &light{}
+					d.DecodeInterface(li)
+					l.lights[id] = li
+				}
+			}
+		default:
+			if err := d.Error(); err != nil {
+				return err
+			}
+			return fmt.Errorf("gamestate: Lighting.DecodeMsgpack failed: unknown key: %s", k)
+		}
+	}
+	if err := d.Error(); err != nil {
+		return fmt.Errorf("gamestate: Lighting.DecodeMsgpack failed: %v", err)
+	}
+	return nil
+}
+
+func (l *Lighting) setDarkness(darkness float64, darknessColor color.RGBA, count int) {
+	if l.darkness == nil {
+		l.darkness = interpolation.New(0)
+	}
+	l.darkness.Set(darkness, count)
+	l.darknessColor = darknessColor
+}
+
+func (l *Lighting) isChangingDarkness() bool {
+	if l.darkness == nil {
+		return false
+	}
+	return l.darkness.IsChanging()
+}
+
+func (l *Lighting) setLight(id int, li *light, radius float64, count int) {
+	if l.lights == nil {
+		l.lights = map[int]*light{}
+	}
+	// Keep the current radius so that the radius can change smoothly.
+	// A new light grows from the radius 0.
+	if old, ok := l.lights[id]; ok {
+		li.radius = old.radius
+	} else {
+		li.radius = interpolation.New(0)
+	}
+	li.radius.Set(radius, count)
+	l.lights[id] = li
+}
+
+func (l *Lighting) eraseLight(id int) {
+	delete(l.lights, id)
+}
+
+func (l *Lighting) SetDarknessForTesting(darkness float64, darknessColor color.RGBA) {
+	l.setDarkness(darkness, darknessColor, 0)
+}
+
+func (l *Lighting) SetTileLightForTesting(id int, mapID, roomID, x, y int, radius float64, clr color.RGBA, flicker float64) {
+	l.setLight(id, &light{
+		mapID:      mapID,
+		roomID:     roomID,
+		targetType: data.LightTargetTypeTile,
+		x:          x,
+		y:          y,
+		color:      clr,
+		flicker:    flicker,
+	}, radius, 0)
+}
+
+func (l *Lighting) Update() {
+	if l.darkness != nil {
+		l.darkness.Update()
+	}
+	for _, li := range l.lights {
+		li.radius.Update()
+	}
+	l.frame++
+}
+
+// flickerRate returns a pseudo-random rate to scale the light radius.
+// This doesn't use the game's random generator so that flickering doesn't affect the game logic.
+func (l *Lighting) flickerRate(id int, li *light) float64 {
+	if li.flicker == 0 {
+		return 1
+	}
+	t := float64(l.frame)
+	v := 0.5*math.Sin(t*0.31+float64(id)) + 0.3*math.Sin(t*0.97+float64(id)*1.7) + 0.2*math.Sin(t*2.3)
+	return 1 - li.flicker*0.15*(v+1)/2
+}
+
+func (l *Lighting) Draw(screen *ebiten.Image, offsetX, offsetY int, game *Game) {
+	darkness := 0.0
+	if l.darkness != nil {
+		darkness = l.darkness.Current()
+	}
+	if darkness == 0 && len(l.lights) == 0 {
+		return
+	}
+
+	ids := make([]int, 0, len(l.lights))
+	for id := range l.lights {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	type visibleLight struct {
+		x      float64
+		y      float64
+		radius float64
+		color  color.RGBA
+	}
+	var lights []visibleLight
+	for _, id := range ids {
+		li := l.lights[id]
+		x, y, ok := li.position(game)
+		if !ok {
+			continue
+		}
+		lights = append(lights, visibleLight{
+			x:      x + float64(offsetX),
+			y:      y + float64(offsetY),
+			radius: li.radius.Current() * l.flickerRate(id, li),
+			color:  li.color,
+		})
+	}
+
+	if darkness > 0 {
+		w, h := screen.Size()
+		if l.overlay != nil {
+			if ow, oh := l.overlay.Size(); ow != w || oh != h {
+				l.overlay.Dispose()
+				l.overlay = nil
+			}
+		}
+		if l.overlay == nil {
+			l.overlay, _ = ebiten.NewImage(w, h, ebiten.FilterDefault)
+		}
+		c := l.darknessColor
+		l.overlay.Fill(color.NRGBA{c.R, c.G, c.B, uint8(darkness * 255)})
+
+		// Cut the darkness with the lights.
+		for _, li := range lights {
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Scale(2*li.radius/lightImageSize, 2*li.radius/lightImageSize)
+			op.GeoM.Translate(li.x-li.radius, li.y-li.radius)
+			op.CompositeMode = ebiten.CompositeModeDestinationOut
+			l.overlay.DrawImage(lightImage, op)
+		}
+		screen.DrawImage(l.overlay, nil)
+	}
+
+	// Add the colors of the lights.
+	for _, li := range lights {
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Scale(2*li.radius/lightImageSize, 2*li.radius/lightImageSize)
+		op.GeoM.Translate(li.x-li.radius, li.y-li.radius)
+		op.ColorM.Scale(float64(li.color.R)/255, float64(li.color.G)/255, float64(li.color.B)/255, 0.25)
+		op.CompositeMode = ebiten.CompositeModeLighter
+		screen.DrawImage(lightImage, op)
+	}
+}
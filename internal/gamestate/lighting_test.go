@@ -0,0 +1,58 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gamestate_test
+
+import (
+	"image/color"
+	"reflect"
+	"testing"
+
+	"github.com/vmihailenco/msgpack"
+
+	. "github.com/hajimehoshi/rpgsnack-runtime/internal/gamestate"
+)
+
+func TestMarshalLighting(t *testing.T) {
+	l := &Lighting{}
+	l.SetDarknessForTesting(0.75, color.RGBA{0x10, 0x20, 0x30, 0xff})
+	l.SetTileLightForTesting(1, 2, 3, 4, 5, 48, color.RGBA{0xff, 0xc0, 0x80, 0xff}, 0.5)
+	l.SetTileLightForTesting(7, 2, 3, 6, 8, 32, color.RGBA{0x80, 0x80, 0xff, 0xff}, 0)
+
+	b, err := msgpack.Marshal(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var l2 *Lighting
+	if err := msgpack.Unmarshal(b, &l2); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(l, l2) {
+		t.Errorf("got: %#v, want: %#v", l2, l)
+	}
+}
+
+func TestUnmarshalLightingUnknownKey(t *testing.T) {
+	b, err := msgpack.Marshal(map[string]interface{}{
+		"darknessColor": []int{0, 0, 0, 0xff},
+		"unknown":       1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var l *Lighting
+	if err := msgpack.Unmarshal(b, &l); err == nil {
+		t.Errorf("msgpack.Unmarshal must return an error for an unknown key")
+	}
+}
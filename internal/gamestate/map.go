@@ -656,15 +656,19 @@ func (m *Map) currentMap() *data.Map {
 	return nil
 }
 
-func (m *Map) CurrentRoom() *data.Room {
+func (m *Map) room(id int) *data.Room {
 	for _, r := range m.currentMap().Rooms() {
-		if r.ID == m.roomID {
+		if r.ID == id {
 			return r
 		}
 	}
 	return nil
 }
 
+func (m *Map) CurrentRoom() *data.Room {
+	return m.room(m.roomID)
+}
+
 func (m *Map) IsPlayerMovingByUserInput() bool {
 	return m.isPlayerMovingByUserInput
 }
@@ -0,0 +1,149 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gamestate
+
+import (
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/lang"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/scene"
+)
+
+// NumberInput is a state of the number input window opened by the input_number command.
+type NumberInput struct {
+	title      string
+	style      data.InputNumberStyle
+	digits     int
+	min        int
+	max        int
+	cancelable bool
+	value      int
+	active     bool
+	canceled   bool
+}
+
+func (n *NumberInput) Active() bool {
+	if n == nil {
+		return false
+	}
+	return n.active
+}
+
+func (n *NumberInput) Title() string {
+	return n.title
+}
+
+func (n *NumberInput) Style() data.InputNumberStyle {
+	return n.style
+}
+
+func (n *NumberInput) Digits() int {
+	return n.digits
+}
+
+func (n *NumberInput) Min() int {
+	return n.min
+}
+
+func (n *NumberInput) Max() int {
+	return n.max
+}
+
+func (n *NumberInput) Cancelable() bool {
+	return n.cancelable
+}
+
+// Value returns the initial value while the input is active, and the entered value after the input is confirmed.
+func (n *NumberInput) Value() int {
+	return n.value
+}
+
+func (n *NumberInput) Canceled() bool {
+	return n.canceled
+}
+
+func (n *NumberInput) Confirm(value int) {
+	if value < n.min || n.max < value {
+		return
+	}
+	n.value = value
+	n.active = false
+}
+
+func (n *NumberInput) Cancel() {
+	if !n.cancelable {
+		return
+	}
+	n.canceled = true
+	n.active = false
+}
+
+func (g *Game) ShowNumberInput(sceneManager *scene.Manager, args *data.CommandArgsInputNumber) {
+	title := ""
+	if args.Title != (data.UUID{}) {
+		title = g.parseMessageSyntax(sceneManager, sceneManager.Game().Texts.Get(lang.Get(), args.Title))
+	}
+
+	digits := args.Digits
+	if digits > data.InputNumberMaxDigits {
+		digits = data.InputNumberMaxDigits
+	}
+	limit := 1
+	for i := 0; i < digits; i++ {
+		limit *= 10
+	}
+	limit--
+
+	max := args.Max
+	if max <= 0 || max > limit {
+		max = limit
+	}
+	min := args.Min
+	if min < 0 {
+		min = 0
+	}
+	if min > max {
+		min = max
+	}
+
+	value := args.Initial
+	if args.InitialType == data.ValueTypeVariable {
+		value = int(g.VariableValue(args.Initial))
+	}
+	if value < min {
+		value = min
+	}
+	if value > max {
+		value = max
+	}
+
+	g.numberInput = &NumberInput{
+		title:      title,
+		style:      args.Style,
+		digits:     digits,
+		min:        min,
+		max:        max,
+		cancelable: args.Cancelable,
+		value:      value,
+		active:     true,
+	}
+}
+
+func (g *Game) HideNumberInput() {
+	g.numberInput = nil
+}
+
+func (g *Game) NumberInput() *NumberInput {
+	return g.numberInput
+}
@@ -0,0 +1,142 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gamestate
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/lang"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/scene"
+)
+
+// TextInput is a state of the text input window opened by the input_text command.
+type TextInput struct {
+	title      string
+	layout     data.InputTextLayout
+	maxLength  int
+	filter     data.InputTextFilter
+	cancelable bool
+	value      string
+	active     bool
+	canceled   bool
+}
+
+func (t *TextInput) Active() bool {
+	if t == nil {
+		return false
+	}
+	return t.active
+}
+
+func (t *TextInput) Title() string {
+	return t.title
+}
+
+func (t *TextInput) Layout() data.InputTextLayout {
+	return t.layout
+}
+
+func (t *TextInput) MaxLength() int {
+	return t.maxLength
+}
+
+func (t *TextInput) Filter() data.InputTextFilter {
+	return t.filter
+}
+
+func (t *TextInput) Cancelable() bool {
+	return t.cancelable
+}
+
+// Value returns the initial value while the input is active, and the entered value after the input is confirmed.
+func (t *TextInput) Value() string {
+	return t.value
+}
+
+func (t *TextInput) Canceled() bool {
+	return t.canceled
+}
+
+func (t *TextInput) Confirm(value string) {
+	t.value = value
+	t.active = false
+}
+
+func (t *TextInput) Cancel() {
+	if !t.cancelable {
+		return
+	}
+	t.canceled = true
+	t.active = false
+}
+
+func (g *Game) ShowTextInput(sceneManager *scene.Manager, args *data.CommandArgsInputText) error {
+	// The entered value is a string, and the value of a non-string attribute can't be overwritten with it.
+	if args.DestType == data.InputTextDestTypeTable {
+		if t := sceneManager.Game().GetTableValueType(args.Table.Name, args.Table.Attr); t != data.TableValueTypeString {
+			return fmt.Errorf("gamestate: input_text can't set the %s value %s:%s", t, args.Table.Name, args.Table.Attr)
+		}
+	}
+	title := ""
+	if args.Title != (data.UUID{}) {
+		title = g.parseMessageSyntax(sceneManager, sceneManager.Game().Texts.Get(lang.Get(), args.Title))
+	}
+	g.textInput = &TextInput{
+		title:      title,
+		layout:     args.Layout,
+		maxLength:  args.MaxLength,
+		filter:     args.Filter,
+		cancelable: args.Cancelable,
+		value:      g.inputTextValue(sceneManager, args),
+		active:     true,
+	}
+	return nil
+}
+
+func (g *Game) HideTextInput() {
+	g.textInput = nil
+}
+
+func (g *Game) TextInput() *TextInput {
+	return g.textInput
+}
+
+func (g *Game) inputTextValue(sceneManager *scene.Manager, args *data.CommandArgsInputText) string {
+	switch args.DestType {
+	case data.InputTextDestTypeVariable:
+		return g.variables.StringVariableValue(args.ID)
+	case data.InputTextDestTypeTable:
+		id := args.Table.ID
+		if args.Table.Type == data.ValueTypeVariable {
+			id = int(g.VariableValue(id))
+		}
+		return g.GetTableValueString(sceneManager, args.Table.Name, id, args.Table.Attr)
+	}
+	return ""
+}
+
+func (g *Game) setInputTextValue(args *data.CommandArgsInputText, value string) {
+	switch args.DestType {
+	case data.InputTextDestTypeVariable:
+		g.variables.SetStringVariableValue(args.ID, value)
+	case data.InputTextDestTypeTable:
+		id := args.Table.ID
+		if args.Table.Type == data.ValueTypeVariable {
+			id = int(g.VariableValue(id))
+		}
+		g.variables.SetTableValue(args.Table.Name, id, args.Table.Attr, value)
+	}
+}
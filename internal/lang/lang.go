@@ -47,3 +47,13 @@ func Normalize(lang language.Tag) language.Tag {
 	}
 	return newLang
 }
+
+// IsRTL reports whether the language is written from right to left.
+func IsRTL(lang language.Tag) bool {
+	base, _ := lang.Base()
+	switch base.String() {
+	case "ar", "fa", "he", "ur":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package picture
+
+import (
+	"image"
+	"math"
+
+	"github.com/golang/groupcache/lru"
+	"github.com/hajimehoshi/ebiten"
+)
+
+// alphaMaskCache is a cache of alpha values per image for touch detection.
+// Reading pixels from GPU is expensive and should be done only once per image.
+var alphaMaskCache = lru.New(32)
+
+type alphaMask struct {
+	alphas []byte
+	width  int
+	height int
+}
+
+func getAlphaMask(img *ebiten.Image) *alphaMask {
+	if m, ok := alphaMaskCache.Get(img); ok {
+		return m.(*alphaMask)
+	}
+	m := newAlphaMask(img)
+	alphaMaskCache.Add(img, m)
+	return m
+}
+
+func newAlphaMask(img image.Image) *alphaMask {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	m := &alphaMask{
+		alphas: make([]byte, w*h),
+		width:  w,
+		height: h,
+	}
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			_, _, _, a := img.At(b.Min.X+i, b.Min.Y+j).RGBA()
+			m.alphas[j*w+i] = byte(a >> 8)
+		}
+	}
+	return m
+}
+
+func (m *alphaMask) at(x, y int) byte {
+	if x < 0 || y < 0 || x >= m.width || y >= m.height {
+		return 0
+	}
+	return m.alphas[y*m.width+x]
+}
+
+// touchable reports whether the point (x, y) in the image coordinates is touchable with the given threshold.
+func (m *alphaMask) touchable(x, y float64, threshold int) bool {
+	if threshold <= 0 {
+		return true
+	}
+	return int(m.at(int(math.Floor(x)), int(math.Floor(y)))) >= threshold
+}
+
+func AlphaMaskTouchableForTesting(img image.Image, x, y float64, threshold int) bool {
+	return newAlphaMask(img).touchable(x, y, threshold)
+}
@@ -0,0 +1,54 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package picture_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	. "github.com/hajimehoshi/rpgsnack-runtime/internal/picture"
+)
+
+func TestAlphaMaskTouchable(t *testing.T) {
+	// 2x2 image whose left column is opaque, and whose right column is translucent.
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(0, 0, color.NRGBA{0xff, 0xff, 0xff, 0xff})
+	img.Set(0, 1, color.NRGBA{0xff, 0xff, 0xff, 0xff})
+	img.Set(1, 0, color.NRGBA{0xff, 0xff, 0xff, 0x40})
+	img.Set(1, 1, color.NRGBA{0xff, 0xff, 0xff, 0x40})
+
+	cases := []struct {
+		X         float64
+		Y         float64
+		Threshold int
+		Out       bool
+	}{
+		{X: 0.5, Y: 0.5, Threshold: 0x80, Out: true},
+		{X: 1.5, Y: 0.5, Threshold: 0x80, Out: false},
+		{X: 1.5, Y: 1.5, Threshold: 0x40, Out: true},
+		{X: 0.99, Y: 1.99, Threshold: 0xff, Out: true},
+		{X: 1, Y: 0, Threshold: 0xff, Out: false},
+		{X: 1.5, Y: 0.5, Threshold: 0, Out: true},
+		{X: -0.5, Y: 0.5, Threshold: 1, Out: false},
+		{X: 2, Y: 0.5, Threshold: 1, Out: false},
+	}
+	for _, c := range cases {
+		got := AlphaMaskTouchableForTesting(img, c.X, c.Y, c.Threshold)
+		if got != c.Out {
+			t.Errorf("AlphaMaskTouchableForTesting(img, %v, %v, %d): got: %v, want: %v", c.X, c.Y, c.Threshold, got, c.Out)
+		}
+	}
+}
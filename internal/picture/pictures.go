@@ -23,6 +23,7 @@ import (
 	"github.com/vmihailenco/msgpack"
 
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/assets"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/character"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/easymsgpack"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/interpolation"
@@ -92,7 +93,7 @@ func (p *Pictures) ensurePictures(id int) {
 	}
 }
 
-func (p *Pictures) TouchingPictureID(x, y int) int {
+func (p *Pictures) TouchingPictureID(x, y int, characters []*character.Character) int {
 	tx := float64(x)
 	ty := float64(y)
 	for i := range p.pictures {
@@ -101,21 +102,77 @@ func (p *Pictures) TouchingPictureID(x, y int) int {
 		if pic == nil || pic.image == nil || !pic.touchable {
 			continue
 		}
-		sx, sy := pic.image.Size()
-		var m ebiten.GeoM
-		m.Translate(-pic.x.Current(), -pic.y.Current())
-		m.Rotate(-pic.angle.Current())
-		m.Scale(1.0/pic.scaleX.Current(), 1.0/pic.scaleY.Current())
-		m.Translate(math.Floor(((1+pic.originX)*float64(sx))/2), math.Floor(((1+pic.originY)*float64(sy))/2))
+		parentGeoM, _, ok := p.parentTransform(pic, characters, 0)
+		if !ok {
+			continue
+		}
+		m := pic.geoM()
+		m.Concat(parentGeoM)
+		if !m.IsInvertible() {
+			continue
+		}
+		m.Invert()
 
+		sx, sy := pic.image.Size()
 		nx, ny := m.Apply(tx, ty)
-		if 0 <= nx && nx <= float64(sx) && 0 <= ny && ny <= float64(sy) {
-			return id
+		if nx < 0 || float64(sx) < nx || ny < 0 || float64(sy) < ny {
+			continue
 		}
+		if pic.touchAlphaThreshold > 0 && !getAlphaMask(pic.image).touchable(nx, ny, pic.touchAlphaThreshold) {
+			continue
+		}
+		return id
 	}
 	return 0
 }
 
+// parentTransform returns the geometry matrix and the opacity that the picture inherits from its parent.
+// parentTransform returns false when the parent doesn't exist. Such a picture is neither drawn nor touchable.
+func (p *Pictures) parentTransform(pic *picture, characters []*character.Character, depth int) (ebiten.GeoM, float64, bool) {
+	switch pic.parentType {
+	case data.PictureParentTypePicture:
+		// Attach rejects cycles, but be careful with broken save data.
+		if depth >= len(p.pictures) {
+			return ebiten.GeoM{}, 0, false
+		}
+		if pic.parentID < 0 || len(p.pictures) <= pic.parentID {
+			return ebiten.GeoM{}, 0, false
+		}
+		parent := p.pictures[pic.parentID]
+		if parent == nil {
+			return ebiten.GeoM{}, 0, false
+		}
+		g, o, ok := p.parentTransform(parent, characters, depth+1)
+		if !ok {
+			return ebiten.GeoM{}, 0, false
+		}
+
+		// The origin of the parent doesn't affect the children: (0, 0) of a child is the parent's position.
+		var m ebiten.GeoM
+		m.Scale(parent.scaleX.Current(), parent.scaleY.Current())
+		m.Rotate(parent.angle.Current())
+		m.Translate(parent.x.Current(), parent.y.Current())
+		m.Concat(g)
+		return m, o * parent.opacity.Current(), true
+	case data.PictureParentTypeCharacter:
+		var c *character.Character
+		for _, ch := range characters {
+			if ch != nil && ch.EventID() == pic.parentID {
+				c = ch
+				break
+			}
+		}
+		if c == nil || c.Erased() || !c.Visible() {
+			return ebiten.GeoM{}, 0, false
+		}
+		x, y := c.DrawFootPosition()
+		var m ebiten.GeoM
+		m.Translate(float64(x), float64(y))
+		return m, float64(c.Opacity()) / 255, true
+	}
+	return ebiten.GeoM{}, 1, true
+}
+
 func (p *Pictures) MoveTo(id int, x, y int, count int) {
 	p.ensurePictures(id)
 	if p.pictures[id] == nil {
@@ -164,27 +221,60 @@ func (p *Pictures) ChangeImage(id int, imageName string) {
 	p.pictures[id].changeImage(imageName)
 }
 
-func (p *Pictures) Update() {
+// Attach attaches the picture to the parent picture or character.
+// The picture's position, scale, angle and opacity become relative to the parent.
+func (p *Pictures) Attach(id int, parentType data.PictureParentType, parentID int) error {
+	p.ensurePictures(id)
+	if p.pictures[id] == nil {
+		return nil
+	}
+	if parentType == data.PictureParentTypePicture {
+		pid := parentID
+		for n := 0; n < len(p.pictures); n++ {
+			if pid == id {
+				return fmt.Errorf("picture: attaching picture %d to picture %d makes a cycle", id, parentID)
+			}
+			if pid < 0 || len(p.pictures) <= pid || p.pictures[pid] == nil {
+				break
+			}
+			if p.pictures[pid].parentType != data.PictureParentTypePicture {
+				break
+			}
+			pid = p.pictures[pid].parentID
+		}
+	}
+	p.pictures[id].parentType = parentType
+	p.pictures[id].parentID = parentID
+	return nil
+}
+
+func (p *Pictures) Update(parser MessageSyntaxParser, game *data.Game) {
 	for _, pic := range p.pictures {
 		if pic == nil {
 			continue
 		}
 		pic.update()
+		pic.updateText(parser, game)
 	}
 }
 
-func (p *Pictures) Draw(screen *ebiten.Image, offsetX, offsetY int, priority data.PicturePriorityType) {
+func (p *Pictures) Draw(screen *ebiten.Image, offsetX, offsetY int, priority data.PicturePriorityType, characters []*character.Character) {
 	for _, pic := range p.pictures {
 		if pic == nil {
 			continue
 		}
-		if pic.priority == priority {
-			pic.draw(screen, offsetX, offsetY)
+		if pic.priority != priority {
+			continue
+		}
+		g, o, ok := p.parentTransform(pic, characters, 0)
+		if !ok {
+			continue
 		}
+		pic.draw(screen, offsetX, offsetY, g, o)
 	}
 }
 
-func (p *Pictures) Add(id int, name string, x, y int, scaleX, scaleY, angle, opacity float64, originX, originY float64, blendType data.ShowPictureBlendType, priority data.PicturePriorityType, touchable bool) {
+func (p *Pictures) Add(id int, name string, x, y int, scaleX, scaleY, angle, opacity float64, originX, originY float64, blendType data.ShowPictureBlendType, priority data.PicturePriorityType, touchable bool, touchAlphaThreshold int) {
 	p.ensurePictures(id)
 	var image *ebiten.Image
 	if name != "" {
@@ -204,11 +294,34 @@ func (p *Pictures) Add(id int, name string, x, y int, scaleX, scaleY, angle, opa
 		blendType: blendType,
 		priority:  priority,
 		touchable: touchable,
+
+		touchAlphaThreshold: touchAlphaThreshold,
+	}
+}
+
+// AddText adds a picture that shows the text instead of an image.
+// The text is rendered again when the language or the variables in the text change.
+func (p *Pictures) AddText(id int, textOptions *TextOptions, parser MessageSyntaxParser, game *data.Game, x, y int, scaleX, scaleY, angle, opacity float64, originX, originY float64, blendType data.ShowPictureBlendType, priority data.PicturePriorityType, touchable bool) {
+	// Texts are touchable by their bounding boxes since the gaps between glyphs are transparent.
+	p.Add(id, "", x, y, scaleX, scaleY, angle, opacity, originX, originY, blendType, priority, touchable, 0)
+	pic := p.pictures[id]
+	pic.text = &pictureText{
+		contentID: textOptions.ContentID,
+		text:      textOptions.Text,
+		scale:     textOptions.Scale,
+		color:     textOptions.Color,
+		textAlign: textOptions.TextAlign,
+		wrapWidth: textOptions.WrapWidth,
 	}
+	pic.updateText(parser, game)
 }
 
 func (p *Pictures) Remove(id int) {
 	p.ensurePictures(id)
+	// A rendered text is owned by the picture unlike images from the assets.
+	if pic := p.pictures[id]; pic != nil && pic.text != nil && pic.image != nil {
+		pic.image.Dispose()
+	}
 	p.pictures[id] = nil
 }
 
@@ -227,6 +340,14 @@ type picture struct {
 	blendType data.ShowPictureBlendType
 	priority  data.PicturePriorityType
 	touchable bool
+	text      *pictureText
+
+	// touchAlphaThreshold is the minimum alpha value (0-255) of the pixel to be touched.
+	// If touchAlphaThreshold is 0, the whole bounding box is touchable.
+	touchAlphaThreshold int
+
+	parentType data.PictureParentType
+	parentID   int
 }
 
 func (p *picture) EncodeMsgpack(enc *msgpack.Encoder) error {
@@ -272,6 +393,18 @@ func (p *picture) EncodeMsgpack(enc *msgpack.Encoder) error {
 	e.EncodeString("touchable")
 	e.EncodeBool(p.touchable)
 
+	e.EncodeString("touchAlphaThreshold")
+	e.EncodeInt(p.touchAlphaThreshold)
+
+	e.EncodeString("text")
+	e.EncodeInterface(p.text)
+
+	e.EncodeString("parentType")
+	e.EncodeString(string(p.parentType))
+
+	e.EncodeString("parentId")
+	e.EncodeInt(p.parentID)
+
 	e.EndMap()
 	return e.Flush()
 }
@@ -317,6 +450,17 @@ func (p *picture) DecodeMsgpack(dec *msgpack.Decoder) error {
 			p.priority = data.PicturePriorityType(d.DecodeString())
 		case "touchable":
 			p.touchable = d.DecodeBool()
+		case "touchAlphaThreshold":
+			p.touchAlphaThreshold = d.DecodeInt()
+		case "text":
+			if !d.SkipCodeIfNil() {
+				p.text = &pictureText{}
+				d.DecodeInterface(p.text)
+			}
+		case "parentType":
+			p.parentType = data.PictureParentType(d.DecodeString())
+		case "parentId":
+			p.parentID = d.DecodeInt()
 		}
 	}
 
@@ -355,6 +499,12 @@ func (p *picture) setTint(red, green, blue, gray float64, count int) {
 }
 
 func (p *picture) changeImage(imageName string) {
+	if p.text != nil {
+		p.text = nil
+		if p.image != nil {
+			p.image.Dispose()
+		}
+	}
 	p.imageName = imageName
 	if imageName == "" {
 		p.image = nil
@@ -373,27 +523,54 @@ func (p *picture) update() {
 	p.tint.Update()
 }
 
-func (p *picture) draw(screen *ebiten.Image, offsetX, offsetY int) {
-	if p.image == nil {
+func (p *picture) updateText(parser MessageSyntaxParser, game *data.Game) {
+	if p.text == nil {
+		return
+	}
+	if !p.text.needsUpdate(parser) {
+		return
+	}
+	p.text.renderedVersion = parser.Version()
+	c := p.text.content(parser, game)
+	if !p.text.needsRendering(c) {
 		return
 	}
+	if p.image != nil {
+		p.image.Dispose()
+	}
+	p.image = p.text.render(c)
+}
 
+// geoM returns the geometry matrix of the picture without its parent's.
+func (p *picture) geoM() ebiten.GeoM {
 	sx, sy := p.image.Size()
 
+	var m ebiten.GeoM
+	m.Translate(math.Floor(((-1-p.originX)*float64(sx))/2), math.Floor(((-1-p.originY)*float64(sy))/2))
+	m.Scale(p.scaleX.Current(), p.scaleY.Current())
+	m.Rotate(p.angle.Current())
+	m.Translate(p.x.Current(), p.y.Current())
+	return m
+}
+
+func (p *picture) draw(screen *ebiten.Image, offsetX, offsetY int, parentGeoM ebiten.GeoM, parentOpacity float64) {
+	if p.image == nil {
+		return
+	}
+
 	op := &ebiten.DrawImageOptions{}
-	op.GeoM.Translate(math.Floor(((-1-p.originX)*float64(sx))/2), math.Floor(((-1-p.originY)*float64(sy))/2))
-	op.GeoM.Scale(p.scaleX.Current(), p.scaleY.Current())
-	op.GeoM.Rotate(p.angle.Current())
-	op.GeoM.Translate(p.x.Current(), p.y.Current())
+	op.GeoM = p.geoM()
+	op.GeoM.Concat(parentGeoM)
 	op.GeoM.Translate(float64(offsetX), float64(offsetY))
 
 	p.tint.Apply(&op.ColorM)
-	if p.opacity.Current() < 1 {
-		op.ColorM.Scale(1, 1, 1, p.opacity.Current())
+	if opacity := p.opacity.Current() * parentOpacity; opacity < 1 {
+		op.ColorM.Scale(1, 1, 1, opacity)
 	}
 
 	img := p.image
-	if !isDiagonal(op.ColorM) && !p.tint.IsChanging() {
+	// Rendered texts are not cached as they don't have image names.
+	if !isDiagonal(op.ColorM) && !p.tint.IsChanging() && p.text == nil {
 		img = p.getCachedImage(op.ColorM)
 		op.ColorM = ebiten.ColorM{}
 	}
@@ -0,0 +1,74 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package picture_test
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+	. "github.com/hajimehoshi/rpgsnack-runtime/internal/picture"
+)
+
+func TestAttach(t *testing.T) {
+	cases := []struct {
+		ID       int
+		ParentID int
+		Err      bool
+	}{
+		{
+			ID:       1,
+			ParentID: 1,
+			Err:      true,
+		},
+		{
+			ID:       1,
+			ParentID: 3,
+			Err:      true,
+		},
+		{
+			ID:       1,
+			ParentID: 2,
+			Err:      true,
+		},
+		{
+			ID:       4,
+			ParentID: 3,
+			Err:      false,
+		},
+		{
+			// A missing parent doesn't make a cycle.
+			ID:       1,
+			ParentID: 5,
+			Err:      false,
+		},
+	}
+	for _, c := range cases {
+		p := &Pictures{}
+		for id := 1; id <= 4; id++ {
+			p.Add(id, "", 0, 0, 1, 1, 0, 1, 0, 0, data.ShowPictureBlendTypeNormal, data.PicturePriorityOverlay, false, 0)
+		}
+		// 3 -> 2 -> 1
+		if err := p.Attach(2, data.PictureParentTypePicture, 1); err != nil {
+			t.Fatal(err)
+		}
+		if err := p.Attach(3, data.PictureParentTypePicture, 2); err != nil {
+			t.Fatal(err)
+		}
+		err := p.Attach(c.ID, data.PictureParentTypePicture, c.ParentID)
+		if got := err != nil; got != c.Err {
+			t.Errorf("Attach(%d, picture, %d): got error: %v, want error: %v", c.ID, c.ParentID, err, c.Err)
+		}
+	}
+}
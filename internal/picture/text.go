@@ -0,0 +1,189 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package picture
+
+import (
+	"encoding/hex"
+	"fmt"
+	"image/color"
+	"math"
+	"regexp"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/vmihailenco/msgpack"
+	"golang.org/x/text/language"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/easymsgpack"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/font"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/lang"
+)
+
+type MessageSyntaxParser interface {
+	ParseMessageSyntax(content string) string
+	Version() int
+}
+
+type TextOptions struct {
+	// ContentID is the ID of the text in data.Texts.
+	// If ContentID is zero, Text is used instead.
+	ContentID data.UUID
+
+	// Text is a string that can include message syntax.
+	Text string
+
+	Scale     float64
+	Color     string
+	TextAlign data.TextAlign
+	WrapWidth int
+}
+
+type pictureText struct {
+	contentID data.UUID
+	text      string
+	scale     float64
+	color     string
+	textAlign data.TextAlign
+	wrapWidth int
+
+	// Not dumped
+	rendered        bool
+	renderedContent string
+	renderedLang    language.Tag
+	renderedVersion int
+}
+
+func (t *pictureText) EncodeMsgpack(enc *msgpack.Encoder) error {
+	e := easymsgpack.NewEncoder(enc)
+	e.BeginMap()
+
+	e.EncodeString("contentID")
+	e.EncodeInterface(&t.contentID)
+
+	e.EncodeString("text")
+	e.EncodeString(t.text)
+
+	e.EncodeString("scale")
+	e.EncodeFloat64(t.scale)
+
+	e.EncodeString("color")
+	e.EncodeString(t.color)
+
+	e.EncodeString("textAlign")
+	e.EncodeString(string(t.textAlign))
+
+	e.EncodeString("wrapWidth")
+	e.EncodeInt(t.wrapWidth)
+
+	e.EndMap()
+	return e.Flush()
+}
+
+func (t *pictureText) DecodeMsgpack(dec *msgpack.Decoder) error {
+	d := easymsgpack.NewDecoder(dec)
+
+	n := d.DecodeMapLen()
+	for i := 0; i < n; i++ {
+		switch k := d.DecodeString(); k {
+		case "contentID":
+			d.DecodeInterface(&t.contentID)
+		case "text":
+			t.text = d.DecodeString()
+		case "scale":
+			t.scale = d.DecodeFloat64()
+		case "color":
+			t.color = d.DecodeString()
+		case "textAlign":
+			t.textAlign = data.TextAlign(d.DecodeString())
+		case "wrapWidth":
+			t.wrapWidth = d.DecodeInt()
+		}
+	}
+
+	if err := d.Error(); err != nil {
+		return fmt.Errorf("pictures: pictureText.DecodeMsgpack failed: %v", err)
+	}
+	return nil
+}
+
+func (t *pictureText) content(parser MessageSyntaxParser, game *data.Game) string {
+	c := t.text
+	if t.contentID != (data.UUID{}) {
+		c = game.Texts.Get(lang.Get(), t.contentID)
+	}
+	return parser.ParseMessageSyntax(c)
+}
+
+// needsUpdate reports whether the content must be resolved again
+// because of changes of the language or variables.
+func (t *pictureText) needsUpdate(parser MessageSyntaxParser) bool {
+	return !t.rendered || t.renderedLang != lang.Get() || t.renderedVersion != parser.Version()
+}
+
+// needsRendering reports whether the text must be rendered again.
+func (t *pictureText) needsRendering(content string) bool {
+	return !t.rendered || t.renderedContent != content || t.renderedLang != lang.Get()
+}
+
+func (t *pictureText) render(content string) *ebiten.Image {
+	t.rendered = true
+	t.renderedContent = content
+	t.renderedLang = lang.Get()
+
+	scale := t.scale
+	if scale <= 0 {
+		scale = 1
+	}
+	if t.wrapWidth > 0 {
+		content = font.Wrap(content, int(float64(t.wrapWidth)/scale))
+	}
+
+	w, h := font.MeasureSize(content)
+	if w == 0 || h == 0 {
+		return nil
+	}
+	sw := int(math.Ceil(float64(w) * scale))
+	sh := int(math.Ceil(float64(h) * scale))
+
+	x := 0
+	switch t.textAlign {
+	case data.TextAlignCenter:
+		x = sw / 2
+	case data.TextAlignRight:
+		x = sw
+	}
+
+	img, _ := ebiten.NewImage(sw, sh, ebiten.FilterDefault)
+	font.DrawText(img, content, x, 0, &font.DrawTextOptions{
+		Scale:     scale,
+		TextAlign: t.textAlign,
+		Color:     parseColor(t.color),
+	})
+	return img
+}
+
+var reColor = regexp.MustCompile(`^#([0-9a-fA-F]{6})$`)
+
+func parseColor(str string) color.Color {
+	m := reColor.FindStringSubmatch(str)
+	if len(m) < 2 {
+		return color.White
+	}
+	bin, err := hex.DecodeString(m[1])
+	if err != nil {
+		panic(fmt.Sprintf("picture: invalid color: %s", m[1]))
+	}
+	return color.RGBA{bin[0], bin[1], bin[2], 0xff}
+}
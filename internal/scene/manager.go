@@ -73,6 +73,9 @@ type Manager struct {
 	news                  []*data.News
 	popupNewsID           int64
 
+	readTextsDirty           bool
+	readTextsSavingRequestID int
+
 	blackImage *ebiten.Image
 	turbo      bool
 
@@ -278,6 +281,8 @@ func (m *Manager) Update() error {
 	default:
 	}
 
+	m.saveReadTextsIfNeeded()
+
 	if input.IsMuteButtonTriggered() {
 		audio.ToggleMute()
 	}
@@ -670,6 +675,68 @@ func (m *Manager) RequestSaveVibrationEnabled(requestID int, vibrationEnabled bo
 	m.Requester().RequestSavePermanent(requestID, bytes)
 }
 
+func (m *Manager) RequestSaveAutoModeEnabled(requestID int, autoModeEnabled bool) {
+	m.permanent.AutoModeEnabled = autoModeEnabled
+	bytes, err := msgpack.Marshal(m.permanent)
+	if err != nil {
+		panic(fmt.Sprintf("scene: msgpack encoding error: %v", err))
+	}
+	m.Requester().RequestSavePermanent(requestID, bytes)
+}
+
+func (m *Manager) RequestSaveSkipModeEnabled(requestID int, skipModeEnabled bool) {
+	m.permanent.SkipModeEnabled = skipModeEnabled
+	bytes, err := msgpack.Marshal(m.permanent)
+	if err != nil {
+		panic(fmt.Sprintf("scene: msgpack encoding error: %v", err))
+	}
+	m.Requester().RequestSavePermanent(requestID, bytes)
+}
+
+func (m *Manager) AutoModeEnabled() bool {
+	return m.permanent.AutoModeEnabled
+}
+
+func (m *Manager) SkipModeEnabled() bool {
+	return m.permanent.SkipModeEnabled
+}
+
+func (m *Manager) IsTextRead(id data.UUID) bool {
+	return m.permanent.ReadTexts[id.String()]
+}
+
+// MarkTextRead marks the text as read.
+// The permanent data is saved asynchronously at the next Update.
+func (m *Manager) MarkTextRead(id data.UUID) {
+	if m.IsTextRead(id) {
+		return
+	}
+	if m.permanent.ReadTexts == nil {
+		m.permanent.ReadTexts = map[string]bool{}
+	}
+	m.permanent.ReadTexts[id.String()] = true
+	m.readTextsDirty = true
+}
+
+func (m *Manager) saveReadTextsIfNeeded() {
+	if m.readTextsSavingRequestID != 0 {
+		if m.ReceiveResultIfExists(m.readTextsSavingRequestID) == nil {
+			return
+		}
+		m.readTextsSavingRequestID = 0
+	}
+	if !m.readTextsDirty {
+		return
+	}
+	bytes, err := msgpack.Marshal(m.permanent)
+	if err != nil {
+		panic(fmt.Sprintf("scene: msgpack encoding error: %v", err))
+	}
+	m.readTextsSavingRequestID = m.GenerateRequestID()
+	m.Requester().RequestSavePermanent(m.readTextsSavingRequestID, bytes)
+	m.readTextsDirty = false
+}
+
 func (m *Manager) PermanentVariableValue(id int) int64 {
 	if len(m.permanent.Variables) < id+1 {
 		zeros := make([]int64, id+1-len(m.permanent.Variables))
@@ -845,3 +912,14 @@ func (m *Manager) RespondAsset(id int, success bool, data []byte) {
 		}
 	}()
 }
+
+func (m *Manager) RespondInputText(id int, success bool, text string) {
+	go func() {
+		m.resultCh <- RequestResult{
+			ID:        id,
+			Type:      RequestTypeInputText,
+			Succeeded: success,
+			Data:      []byte(text),
+		}
+	}()
+}
@@ -25,4 +25,10 @@ type Permanent struct {
 	BGMMute           int             `msgpack:"bgm_mute"`
 	SEMute            int             `msgpack:"se_mute"`
 	VibrationDisabled bool            `msgpack:"vibrationDisabled"`
+	AutoModeEnabled   bool            `msgpack:"autoModeEnabled"`
+	SkipModeEnabled   bool            `msgpack:"skipModeEnabled"`
+
+	// ReadTexts is a set of the text UUIDs that have been shown in messages.
+	// This is shared among playthroughs.
+	ReadTexts map[string]bool `msgpack:"readTexts"`
 }
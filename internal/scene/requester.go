@@ -32,6 +32,7 @@ type Requester interface {
 	RequestVibration(vibrationType string)
 	RequestAsset(requestID int, key string)
 	RequestMarkNewsRead(newsID int64)
+	RequestInputText(requestID int, title string, text string, maxLength int, layout string)
 }
 
 type RequestType int
@@ -49,6 +50,7 @@ const (
 	RequestTypeShareImage
 	RequestTypeChangeLanguage
 	RequestTypeAsset
+	RequestTypeInputText
 )
 
 type RequestResult struct {
@@ -20,6 +20,7 @@ import (
 
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/audio"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/consts"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/input"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/lang"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/scene"
@@ -36,6 +37,10 @@ type AdvancedSettingsScene struct {
 	languageButton   *ui.Button
 	vibrationLabel   *ui.Label
 	vibrationButton  *ui.SwitchButton
+	autoModeLabel    *ui.Label
+	autoModeButton   *ui.SwitchButton
+	skipModeLabel    *ui.Label
+	skipModeButton   *ui.SwitchButton
 	resetGameButton  *ui.Button
 	bgmLabel         *ui.Label
 	bgmSlider        *ui.Slider
@@ -53,6 +58,9 @@ type AdvancedSettingsScene struct {
 	initialized bool
 	baseX       int
 	baseY       int
+
+	// rtl indicates whether the UI is laid out for a right-to-left language.
+	rtl bool
 }
 
 func (s *AdvancedSettingsScene) calcButtonY(index int) int {
@@ -64,18 +72,42 @@ func (s *AdvancedSettingsScene) initUI(sceneManager *scene.Manager) {
 	s.baseX = (w/consts.TileScale - 120) / 2
 	s.baseY = (h - 640) / (2 * consts.TileScale)
 
-	s.settingsLabel = ui.NewLabel(16, s.baseY+8)
+	// The labels and the controls are mirrored in a right-to-left language.
+	s.rtl = lang.IsRTL(lang.Get())
+	settingsLabelX, labelX, sliderX, switchX := 16, s.baseX, s.baseX+48, s.baseX+72
+	if s.rtl {
+		settingsLabelX, labelX, sliderX, switchX = w/consts.TileScale-16, s.baseX+120, s.baseX+22, s.baseX
+	}
+	newLabel := func(x, y int) *ui.Label {
+		l := ui.NewLabel(x, y)
+		if s.rtl {
+			l.TextAlign = data.TextAlignRight
+		}
+		return l
+	}
+
+	s.settingsLabel = newLabel(settingsLabelX, s.baseY+8)
 	s.languageButton = ui.NewButton(s.baseX, s.calcButtonY(1), 120, 20, "system/click")
-	s.bgmLabel = ui.NewLabel(s.baseX, s.calcButtonY(2)+4)
-	s.bgmSlider = ui.NewSlider(s.baseX+48, s.calcButtonY(2), 50, 0, 100, sceneManager.BGMVolume())
-	s.seLabel = ui.NewLabel(s.baseX, s.calcButtonY(3)+4)
-	s.seSlider = ui.NewSlider(s.baseX+48, s.calcButtonY(3), 50, 0, 100, sceneManager.SEVolume())
-	s.vibrationLabel = ui.NewLabel(s.baseX, s.calcButtonY(4)+4)
-	s.vibrationButton = ui.NewSwitchButton(s.baseX+72, s.calcButtonY(4), sceneManager.VibrationEnabled())
-	s.resetGameButton = ui.NewButton(s.baseX, s.calcButtonY(5), 120, 20, "system/click")
+	s.bgmLabel = newLabel(labelX, s.calcButtonY(2)+4)
+	s.bgmSlider = ui.NewSlider(sliderX, s.calcButtonY(2), 50, 0, 100, sceneManager.BGMVolume())
+	s.seLabel = newLabel(labelX, s.calcButtonY(3)+4)
+	s.seSlider = ui.NewSlider(sliderX, s.calcButtonY(3), 50, 0, 100, sceneManager.SEVolume())
+	s.vibrationLabel = newLabel(labelX, s.calcButtonY(4)+4)
+	s.vibrationButton = ui.NewSwitchButton(switchX, s.calcButtonY(4), sceneManager.VibrationEnabled())
+	// The rows below the vibration switch are moved up when the vibration is not available.
+	row := 5
+	if !sceneManager.Game().System.Vibration {
+		row = 4
+	}
+	s.autoModeLabel = newLabel(labelX, s.calcButtonY(row)+4)
+	s.autoModeButton = ui.NewSwitchButton(switchX, s.calcButtonY(row), sceneManager.AutoModeEnabled())
+	s.skipModeLabel = newLabel(labelX, s.calcButtonY(row+1)+4)
+	s.skipModeButton = ui.NewSwitchButton(switchX, s.calcButtonY(row+1), sceneManager.SkipModeEnabled())
+	s.resetGameButton = ui.NewButton(s.baseX, s.calcButtonY(row+2), 120, 20, "system/click")
 	s.closeButton = ui.NewButton(s.baseX, s.calcButtonY(8), 120, 20, "system/cancel")
 
 	s.languagePopup = ui.NewPopup((h/consts.TileScale-160)/2, 160)
+	s.languageButtons = nil
 
 	for i, l := range sceneManager.Game().Texts.Languages() {
 		i := i // i is captured by the below closure and it is needed to copy here.
@@ -104,6 +136,16 @@ func (s *AdvancedSettingsScene) initUI(sceneManager *scene.Manager) {
 		sceneManager.RequestSaveVibrationEnabled(s.waitingRequestID, value)
 	})
 
+	s.autoModeButton.SetOnToggled(func(_ *ui.SwitchButton, value bool) {
+		s.waitingRequestID = sceneManager.GenerateRequestID()
+		sceneManager.RequestSaveAutoModeEnabled(s.waitingRequestID, value)
+	})
+
+	s.skipModeButton.SetOnToggled(func(_ *ui.SwitchButton, value bool) {
+		s.waitingRequestID = sceneManager.GenerateRequestID()
+		sceneManager.RequestSaveSkipModeEnabled(s.waitingRequestID, value)
+	})
+
 	s.bgmSlider.SetOnValueChanged(func(slider *ui.Slider, value int) {
 		audio.SetBGMVolume(float64(value) / 100.0)
 	})
@@ -130,7 +172,11 @@ func (s *AdvancedSettingsScene) initUI(sceneManager *scene.Manager) {
 	})
 
 	s.warningPopup = ui.NewPopup((h/consts.TileScale-128)/2, 128)
-	s.warningLabel = ui.NewLabel(16, 8)
+	warningLabelX := 16
+	if s.rtl {
+		warningLabelX = ui.PopupWidth - 16
+	}
+	s.warningLabel = newLabel(warningLabelX, 8)
 	s.warningYesButton = ui.NewButton((ui.PopupWidth-120)/2, 72, 120, 20, "system/click")
 	s.warningNoButton = ui.NewButton((ui.PopupWidth-120)/2, 96, 120, 20, "system/cancel")
 	s.warningPopup.AddChild(s.warningLabel)
@@ -151,15 +197,13 @@ func (s *AdvancedSettingsScene) initUI(sceneManager *scene.Manager) {
 		s.vibrationLabel.Hide()
 		s.vibrationButton.Hide()
 	}
-
-	if !sceneManager.Game().System.Vibration {
-		s.resetGameButton.SetY(s.calcButtonY(4))
-	}
 }
 
 func (s *AdvancedSettingsScene) updateTexts() {
 	s.settingsLabel.Text = texts.Text(lang.Get(), texts.TextIDAdvancedSettings)
 	s.vibrationLabel.Text = texts.Text(lang.Get(), texts.TextIDVibration)
+	s.autoModeLabel.Text = texts.Text(lang.Get(), texts.TextIDAutoMode)
+	s.skipModeLabel.Text = texts.Text(lang.Get(), texts.TextIDSkipMode)
 	s.languageButton.SetText(texts.Text(lang.Get(), texts.TextIDLanguage))
 	s.bgmLabel.Text = texts.Text(lang.Get(), texts.TextIDBGMVolume)
 	s.seLabel.Text = texts.Text(lang.Get(), texts.TextIDSEVolume)
@@ -184,7 +228,8 @@ func (s *AdvancedSettingsScene) Update(sceneManager *scene.Manager) error {
 		return nil
 	}
 
-	if !s.initialized {
+	// The UI is laid out again when the language direction is changed.
+	if !s.initialized || s.rtl != lang.IsRTL(lang.Get()) {
 		s.initUI(sceneManager)
 		s.initialized = true
 	}
@@ -200,6 +245,10 @@ func (s *AdvancedSettingsScene) Update(sceneManager *scene.Manager) error {
 	if !s.languagePopup.Visible() && !s.warningPopup.Visible() {
 		s.vibrationLabel.Update()
 		s.vibrationButton.Update()
+		s.autoModeLabel.Update()
+		s.autoModeButton.Update()
+		s.skipModeLabel.Update()
+		s.skipModeButton.Update()
 		s.bgmLabel.Update()
 		s.bgmSlider.Update()
 		s.seLabel.Update()
@@ -262,6 +311,10 @@ func (s *AdvancedSettingsScene) Draw(screen *ebiten.Image) {
 	s.closeButton.Draw(screen)
 	s.vibrationLabel.Draw(screen)
 	s.vibrationButton.Draw(screen)
+	s.autoModeLabel.Draw(screen)
+	s.autoModeButton.Draw(screen)
+	s.skipModeLabel.Draw(screen)
+	s.skipModeButton.Draw(screen)
 	s.languagePopup.Draw(screen)
 	s.warningPopup.Draw(screen)
 }
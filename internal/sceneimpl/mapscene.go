@@ -35,6 +35,7 @@ import (
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/texts"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/tileset"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/ui"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/window"
 )
 
 const (
@@ -64,8 +65,11 @@ type MapScene struct {
 	inventory            *ui.Inventory
 	itemPreviewPopup     *ui.ItemPreviewPopup
 	minigamePopup        *ui.MinigamePopup
+	textInputPopup       *ui.TextInputPopup
+	numberInputPopup     *ui.NumberInputPopup
 	titleView            *ui.TitleView
 	credits              *ui.Credits
+	backlog              *ui.Backlog
 	markerAnimationFrame int
 	waitingRequestID     int
 	initialized          bool
@@ -225,9 +229,12 @@ func (m *MapScene) initUI(sceneManager *scene.Manager) {
 	ty := consts.CeilDiv(screenH, consts.TileScale) - m.inventoryHeight - itemPreviewPopupMargin
 	m.itemPreviewPopup = ui.NewItemPreviewPopup(ty)
 	m.minigamePopup = ui.NewMinigamePopup(ty)
+	m.textInputPopup = ui.NewTextInputPopup((screenH/consts.TileScale - ui.TextInputPopupHeight) / 2)
+	m.numberInputPopup = ui.NewNumberInputPopup(screenH / consts.TileScale)
 	m.quitPopup.AddChild(m.quitLabel)
 
 	m.credits = ui.NewCredits()
+	m.backlog = ui.NewBacklog()
 
 	m.quitYesButton.SetOnPressed(func(_ *ui.Button) {
 		if m.gameState.IsAutoSaveEnabled() && !m.gameState.Map().IsBlockingEventExecuting() && !m.gameState.Map().IsPlayerMovingByUserInput() {
@@ -248,6 +255,18 @@ func (m *MapScene) initUI(sceneManager *scene.Manager) {
 		m.gameHeader.SetOnTitleButtonPressed(func() {
 			m.quitPopup.Show()
 		})
+		m.gameHeader.SetOnBacklogButtonPressed(func() {
+			var entries []ui.BacklogEntry
+			for _, h := range m.gameState.MessageHistory() {
+				entries = append(entries, ui.BacklogEntry{
+					Speaker: h.Speaker,
+					Text:    h.Text,
+					Choice:  h.Type == window.HistoryEntryTypeChoice,
+				})
+			}
+			m.backlog.SetEntries(entries)
+			m.backlog.Show()
+		})
 		m.gameHeader.SetOnCameraButtonPressed(func() {
 			// TODO: Hide the game header
 			sceneManager.ShareScreenshot()
@@ -343,6 +362,11 @@ func (m *MapScene) initUI(sceneManager *scene.Manager) {
 		m.gameState.Items().SetCombineItem(0)
 	})
 	// TODO: ItemPreviewPopup is not standarized as the other Popups
+	m.textInputPopup.SetOnRequestNativeInput(func(title string, text string, maxLength int, layout data.InputTextLayout) {
+		m.waitingRequestID = sceneManager.GenerateRequestID()
+		sceneManager.Requester().RequestInputText(m.waitingRequestID, title, text, maxLength, string(layout))
+	})
+
 	m.itemPreviewPopup.SetOnActionPressed(func(_ *ui.ItemPreviewPopup) {
 		if m.gameState.Map().IsBlockingEventExecuting() {
 			return
@@ -464,6 +488,13 @@ func (m *MapScene) receiveRequest(sceneManager *scene.Manager) bool {
 			sceneManager.Requester().RequestSendAnalytics(fmt.Sprintf("minigame%d_reward", mg.ID()), "")
 			m.minigamePopup.ActivateBoostMode()
 		}
+	case scene.RequestTypeInputText:
+		// When the native keyboard is not available, the on-screen keyboard is used as it is.
+		// When the text from the native keyboard is changed by the filter or the max length,
+		// the player checks the text on the on-screen keyboard before confirming it.
+		if r.Succeeded && m.textInputPopup.SetValue(string(r.Data)) {
+			m.textInputPopup.Confirm()
+		}
 	}
 	return false
 }
@@ -478,6 +509,9 @@ func (m *MapScene) isUIBusy() bool {
 	if m.credits.Visible() {
 		return true
 	}
+	if m.backlog.Visible() {
+		return true
+	}
 	return false
 }
 
@@ -493,7 +527,7 @@ func (m *MapScene) updateUI(sceneManager *scene.Manager) {
 	m.storeErrorPopup.Update()
 
 	if m.gameHeader != nil {
-		m.gameHeader.Update(m.quitPopup.Visible() || m.credits.Visible())
+		m.gameHeader.Update(m.quitPopup.Visible() || m.credits.Visible() || m.backlog.Visible())
 	}
 
 	m.itemPreviewPopup.Update(l)
@@ -510,6 +544,24 @@ func (m *MapScene) updateUI(sceneManager *scene.Manager) {
 	m.minigamePopup.Update(m.gameState.Minigame())
 	m.minigamePopup.SetAdsLoaded(sceneManager.RewardedAdsLoaded())
 
+	if t := m.gameState.TextInput(); t.Active() {
+		if !m.textInputPopup.Visible() {
+			m.textInputPopup.Show(t)
+		}
+	} else {
+		m.textInputPopup.Hide()
+	}
+	m.textInputPopup.Update()
+
+	if n := m.gameState.NumberInput(); n.Active() {
+		if !m.numberInputPopup.Visible() {
+			m.numberInputPopup.Show(n)
+		}
+	} else {
+		m.numberInputPopup.Hide()
+	}
+	m.numberInputPopup.Update()
+
 	if m.titleView != nil {
 		if id := m.titleView.WaitingRequestID(); id != 0 {
 			if sceneManager.ReceiveResultIfExists(id) != nil {
@@ -522,6 +574,7 @@ func (m *MapScene) updateUI(sceneManager *scene.Manager) {
 
 	m.credits.Update()
 	m.credits.SetCloseButtonVisible(m.gameState.ShouldShowCreditsCloseButton())
+	m.backlog.Update()
 
 	if m.quitPopup.HandleInput(0, 0) {
 		return
@@ -535,6 +588,12 @@ func (m *MapScene) updateUI(sceneManager *scene.Manager) {
 	if m.minigamePopup.HandleInput(0, 0) {
 		return
 	}
+	if m.textInputPopup.HandleInput(0, 0) {
+		return
+	}
+	if m.numberInputPopup.HandleInput(0, 0) {
+		return
+	}
 }
 
 func (m *MapScene) updateInventory(sceneManager *scene.Manager) {
@@ -652,6 +711,12 @@ func (m *MapScene) handleBackButton(sceneManager *scene.Manager) {
 		return
 	}
 
+	if m.backlog.Visible() {
+		audio.PlaySE("system/cancel", 1.0)
+		m.backlog.Hide()
+		return
+	}
+
 	if m.storeErrorPopup.Visible() {
 		audio.PlaySE("system/cancel", 1.0)
 		m.storeErrorPopup.Hide()
@@ -678,6 +743,27 @@ func (m *MapScene) handleBackButton(sceneManager *scene.Manager) {
 		return
 	}
 
+	if m.textInputPopup.Visible() {
+		if m.gameState.TextInput().Cancelable() {
+			audio.PlaySE("system/cancel", 1.0)
+			m.textInputPopup.Cancel()
+		}
+		return
+	}
+
+	if m.numberInputPopup.Visible() {
+		if m.gameState.NumberInput().Cancelable() {
+			audio.PlaySE("system/cancel", 1.0)
+			m.numberInputPopup.Cancel()
+		}
+		return
+	}
+
+	if m.gameState.CancelChoices() {
+		audio.PlaySE("system/cancel", 1.0)
+		return
+	}
+
 	if m.titleView == nil {
 		audio.PlaySE("system/click", 1.0)
 		m.quitPopup.Show()
@@ -785,6 +871,11 @@ func (m *MapScene) Draw(screen *ebiten.Image) {
 		return
 	}
 
+	if m.backlog.Visible() {
+		m.backlog.Draw(screen)
+		return
+	}
+
 	// Filling with black instead of clearing is necessary for tinting.
 	// See the change 701eb1105ec126f09680f6a185ed1b1bf5235950.
 	m.screenImage.Fill(color.Black)
@@ -826,6 +917,7 @@ func (m *MapScene) Draw(screen *ebiten.Image) {
 	}
 
 	m.gameState.DrawWeather(m.screenImage)
+	m.gameState.DrawLighting(m.screenImage, 0, m.offsetY/consts.TileScale)
 	m.gameState.DrawScreen(m.screenImage)
 
 	tintScreenImage := m.screenImage
@@ -870,6 +962,8 @@ func (m *MapScene) Draw(screen *ebiten.Image) {
 
 	m.itemPreviewPopup.Draw(screen)
 	m.minigamePopup.Draw(screen)
+	m.textInputPopup.Draw(screen)
+	m.numberInputPopup.Draw(screen)
 	m.inventory.Draw(screen)
 
 	m.gameState.DrawWindows(screen, 0, m.offsetY/consts.TileScale, m.windowOffsetY/consts.TileScale)
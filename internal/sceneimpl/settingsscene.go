@@ -148,9 +148,18 @@ func (s *SettingsScene) Update(sceneManager *scene.Manager) error {
 	if sceneManager.SponsorTier() > 0 {
 		s.updateCreditsButton.Show()
 		s.creditsButton.SetWidth(76)
+		// The buttons are swapped in a right-to-left language.
+		if lang.IsRTL(lang.Get()) {
+			s.creditsButton.SetX(s.baseX + 44)
+			s.updateCreditsButton.SetX(s.baseX)
+		} else {
+			s.creditsButton.SetX(s.baseX)
+			s.updateCreditsButton.SetX(s.baseX + 80)
+		}
 	} else {
 		s.updateCreditsButton.Hide()
 		s.creditsButton.SetWidth(120)
+		s.creditsButton.SetX(s.baseX)
 	}
 
 	if s.waitingRequestID != 0 {
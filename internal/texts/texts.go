@@ -55,6 +55,11 @@ const (
 	TextIDBuy
 	TextIDPurchased
 	TextIDDetails
+	TextIDBacklog
+	TextIDAutoMode
+	TextIDSkipMode
+	TextIDInputSpace
+	TextIDInputCase
 )
 
 func Text(lang language.Tag, id TextID) string {
@@ -107,6 +112,11 @@ and connect to the network.`,
 		TextIDBuy:              "Buy",
 		TextIDPurchased:        "Purchased",
 		TextIDDetails:          "Details",
+		TextIDBacklog:          "Log",
+		TextIDAutoMode:         "Auto Mode",
+		TextIDSkipMode:         "Skip Read Text",
+		TextIDInputSpace:       "Space",
+		TextIDInputCase:        "Aa",
 	},
 	language.German: {
 		TextIDNewGame:      "Neues Spiel",
@@ -150,6 +160,11 @@ und mit dem Internet verbindet bist.`,
 		TextIDBuy:              "Kaufen",
 		TextIDPurchased:        "Gekauft",
 		TextIDDetails:          "Details",
+		TextIDBacklog:          "Verlauf",
+		TextIDAutoMode:         "Automodus",
+		TextIDSkipMode:         "Schnellvorlauf",
+		TextIDInputSpace:       "Leer",
+		TextIDInputCase:        "Aa",
 	},
 	language.Spanish: {
 		TextIDNewGame:      "Nuevo Juego",
@@ -191,6 +206,11 @@ sesión y conectarse a internet`,
 		TextIDBuy:              "Compar",
 		TextIDPurchased:        "Comprado",
 		TextIDDetails:          "Detalles",
+		TextIDBacklog:          "Historial",
+		TextIDAutoMode:         "Modo auto",
+		TextIDSkipMode:         "Saltar leído",
+		TextIDInputSpace:       "Espacio",
+		TextIDInputCase:        "Aa",
 	},
 	language.Portuguese: {
 		TextIDNewGame:      "Novo Jogo",
@@ -234,6 +254,11 @@ o login e se conectar à internet`,
 		TextIDBuy:              "Compar",
 		TextIDPurchased:        "Comprado",
 		TextIDDetails:          "Detalhes",
+		TextIDBacklog:          "Histórico",
+		TextIDAutoMode:         "Modo auto",
+		TextIDSkipMode:         "Pular lido",
+		TextIDInputSpace:       "Espaço",
+		TextIDInputCase:        "Aa",
 	},
 	language.Japanese: {
 		TextIDNewGame:      "はじめから",
@@ -275,6 +300,11 @@ o login e se conectar à internet`,
 		TextIDBuy:              "購入する",
 		TextIDPurchased:        "購入済み",
 		TextIDDetails:          "詳細",
+		TextIDBacklog:          "履歴",
+		TextIDAutoMode:         "オートモード",
+		TextIDSkipMode:         "既読スキップ",
+		TextIDInputSpace:       "空白",
+		TextIDInputCase:        "Aa",
 	},
 	language.SimplifiedChinese: {
 		TextIDNewGame:      "新游戏",
@@ -315,6 +345,11 @@ o login e se conectar à internet`,
 		TextIDBuy:              "购买",
 		TextIDPurchased:        "已购买",
 		TextIDDetails:          "更多细节",
+		TextIDBacklog:          "记录",
+		TextIDAutoMode:         "自动模式",
+		TextIDSkipMode:         "跳过已读",
+		TextIDInputSpace:       "空格",
+		TextIDInputCase:        "Aa",
 	},
 	language.TraditionalChinese: {
 		TextIDNewGame:      "新遊戲",
@@ -355,6 +390,11 @@ o login e se conectar à internet`,
 		TextIDBuy:              "購買",
 		TextIDPurchased:        "已購買",
 		TextIDDetails:          "更多細節",
+		TextIDBacklog:          "記錄",
+		TextIDAutoMode:         "自動模式",
+		TextIDSkipMode:         "跳過已讀",
+		TextIDInputSpace:       "空格",
+		TextIDInputCase:        "Aa",
 	},
 	language.Korean: {
 		TextIDNewGame:      "처음부터",
@@ -396,5 +436,10 @@ o login e se conectar à internet`,
 		TextIDBuy:              "구매하기",
 		TextIDPurchased:        "구입 완료",
 		TextIDDetails:          "자세히",
+		TextIDBacklog:          "기록",
+		TextIDAutoMode:         "자동 모드",
+		TextIDSkipMode:         "읽은 글 스킵",
+		TextIDInputSpace:       "공백",
+		TextIDInputCase:        "Aa",
 	},
 }
@@ -0,0 +1,216 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ui
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten"
+	"golang.org/x/text/language"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/assets"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/consts"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/font"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/input"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/lang"
+)
+
+const (
+	backlogMarginX   = 16
+	backlogMarginTop = 16 * consts.TileScale
+	backlogSpacing   = 8
+)
+
+var (
+	backlogChoiceColor  = color.RGBA{0xff, 0xd8, 0x60, 0xff}
+	backlogSpeakerColor = color.RGBA{0xa0, 0xc0, 0xff, 0xff}
+)
+
+type BacklogEntry struct {
+	Speaker string
+	Text    string
+	Choice  bool
+}
+
+// backlogItem is a wrapped entry ready to render.
+type backlogItem struct {
+	speaker string
+	text    string
+	height  int
+}
+
+// Backlog is a full-screen view to read the messages shown so far.
+type Backlog struct {
+	closeButton *Button
+	visible     bool
+	entries     []BacklogEntry
+
+	// scrollY is the distance from the bottom in pixels. 0 means the newest entry is shown.
+	scrollY  int
+	dragging bool
+	lastY    int
+
+	screenHeight int
+
+	items         []backlogItem
+	itemsLang     language.Tag
+	itemsDirty    bool
+	contentHeight int
+}
+
+func NewBacklog() *Backlog {
+	closeButton := NewImageButton(
+		140,
+		4,
+		assets.GetImage("system/common/cancel_off.png"),
+		assets.GetImage("system/common/cancel_on.png"),
+		"system/cancel",
+	)
+
+	b := &Backlog{
+		closeButton: closeButton,
+	}
+	closeButton.SetOnPressed(func(_ *Button) {
+		b.Hide()
+	})
+	return b
+}
+
+func (b *Backlog) SetEntries(entries []BacklogEntry) {
+	b.entries = entries
+	b.itemsDirty = true
+}
+
+func (b *Backlog) Visible() bool {
+	return b.visible
+}
+
+func (b *Backlog) Show() {
+	b.visible = true
+	b.scrollY = 0
+	b.dragging = false
+}
+
+func (b *Backlog) Hide() {
+	b.visible = false
+}
+
+func (b *Backlog) updateItems() {
+	l := lang.Get()
+	if !b.itemsDirty && b.itemsLang == l {
+		return
+	}
+	b.itemsDirty = false
+	b.itemsLang = l
+
+	const width = (consts.MapWidth*consts.TileScale - 2*backlogMarginX) / consts.TextScale
+	b.items = make([]backlogItem, len(b.entries))
+	b.contentHeight = 0
+	for i, e := range b.entries {
+		str := e.Text
+		if e.Choice {
+			str = "> " + str
+		}
+		str = font.Wrap(str, width)
+		_, h := font.MeasureSize(str)
+		h *= consts.TextScale
+		if e.Speaker != "" {
+			h += font.RenderingLineHeight * consts.TextScale
+		}
+		b.items[i] = backlogItem{
+			speaker: e.Speaker,
+			text:    str,
+			height:  h,
+		}
+		b.contentHeight += h + backlogSpacing
+	}
+}
+
+func (b *Backlog) Update() {
+	if !b.visible {
+		return
+	}
+	// TODO: This function should return immediately when input is handled.
+	b.closeButton.HandleInput(0, 0)
+	if !b.visible {
+		return
+	}
+
+	_, y := input.Position()
+	switch {
+	case input.Triggered() && !includesInput(0, 0, b.closeButton.Region()):
+		b.dragging = true
+		b.lastY = y
+	case input.Pressed() && b.dragging:
+		b.scrollY += y - b.lastY
+		b.lastY = y
+	default:
+		b.dragging = false
+	}
+	if _, dy := input.Wheel(); dy != 0 {
+		b.scrollY += int(dy * font.RenderingLineHeight * consts.TextScale)
+	}
+
+	b.updateItems()
+	if max := b.contentHeight - (b.screenHeight - backlogMarginTop - backlogMarginX); b.scrollY > max {
+		b.scrollY = max
+	}
+	if b.scrollY < 0 {
+		b.scrollY = 0
+	}
+}
+
+func (b *Backlog) Draw(screen *ebiten.Image) {
+	if !b.visible {
+		return
+	}
+	screen.Fill(color.Black)
+
+	_, sy := screen.Size()
+	b.screenHeight = sy
+	y := sy - backlogMarginX + b.scrollY
+	b.updateItems()
+	for i := len(b.items) - 1; i >= 0; i-- {
+		item := &b.items[i]
+		y -= item.height
+		if y < backlogMarginTop-item.height {
+			break
+		}
+		if y < sy {
+			ty := y
+			if item.speaker != "" {
+				op := &font.DrawTextOptions{
+					Scale:    consts.TextScale,
+					Color:    backlogSpeakerColor,
+					Language: b.itemsLang,
+				}
+				font.DrawText(screen, item.speaker, backlogMarginX, ty, op)
+				ty += font.RenderingLineHeight * consts.TextScale
+			}
+			c := color.Color(color.White)
+			if b.entries[i].Choice {
+				c = backlogChoiceColor
+			}
+			op := &font.DrawTextOptions{
+				Scale:    consts.TextScale,
+				Color:    c,
+				Language: b.itemsLang,
+			}
+			font.DrawText(screen, item.text, backlogMarginX, ty, op)
+		}
+		y -= backlogSpacing
+	}
+	b.closeButton.DrawAsChild(screen, 0, 0)
+}
@@ -47,6 +47,7 @@ type Button struct {
 	soundName     string
 	showFrame     bool
 	textColor     color.Color
+	fontName      string
 	Lang          language.Tag
 
 	onPressed func(button *Button)
@@ -125,6 +126,11 @@ func (b *Button) SetColor(clr color.Color) {
 	b.textColor = clr
 }
 
+// SetFont sets the name of the font file under images/fonts. An empty string means the default fonts.
+func (b *Button) SetFont(name string) {
+	b.fontName = name
+}
+
 func (b *Button) Show() {
 	b.visible = true
 }
@@ -258,6 +264,7 @@ func (b *Button) DrawAsChild(screen *ebiten.Image, offsetX, offsetY int) {
 		Scale:     consts.TextScale * b.scale,
 		TextAlign: data.TextAlignCenter,
 		Language:  b.Lang,
+		Font:      b.fontName,
 	}
 	if b.dropShadow {
 		dtop.Color = color.Black
@@ -35,6 +35,7 @@ type GameHeader struct {
 	x              int
 	y              int
 	titleButton    *Button
+	backlogButton  *Button
 	cameraButton   *Button
 	blackImage     *ebiten.Image
 	isClosing      bool
@@ -42,8 +43,9 @@ type GameHeader struct {
 	revealRatio    float64
 	autoCloseTimer int
 
-	onTitleButtonPressed  func()
-	onCameraButtonPressed func()
+	onTitleButtonPressed   func()
+	onBacklogButtonPressed func()
+	onCameraButtonPressed  func()
 }
 
 func NewGameHeader() *GameHeader {
@@ -52,6 +54,10 @@ func NewGameHeader() *GameHeader {
 	titleButton.text = texts.Text(l, texts.TextIDMenu)
 	titleButton.disabled = true
 
+	backlogButton := NewTextButton(38, 2, 32, 12, "system/click")
+	backlogButton.text = texts.Text(l, texts.TextIDBacklog)
+	backlogButton.disabled = true
+
 	cameraButton := NewImageButton(142, 0, assets.GetImage("system/common/camera_off.png"), assets.GetImage("system/common/camera_on.png"), "system/camera")
 	cameraButton.disabled = true
 
@@ -62,6 +68,7 @@ func NewGameHeader() *GameHeader {
 		x:              0,
 		y:              0,
 		titleButton:    titleButton,
+		backlogButton:  backlogButton,
 		cameraButton:   cameraButton,
 		blackImage:     blackImage,
 		isOpening:      false,
@@ -73,6 +80,9 @@ func NewGameHeader() *GameHeader {
 	titleButton.SetOnPressed(func(_ *Button) {
 		g.onTitleButtonPressed()
 	})
+	backlogButton.SetOnPressed(func(_ *Button) {
+		g.onBacklogButtonPressed()
+	})
 	cameraButton.SetOnPressed(func(_ *Button) {
 		g.onCameraButtonPressed()
 	})
@@ -84,12 +94,17 @@ func (g *GameHeader) SetOnTitleButtonPressed(f func()) {
 	g.onTitleButtonPressed = f
 }
 
+func (g *GameHeader) SetOnBacklogButtonPressed(f func()) {
+	g.onBacklogButtonPressed = f
+}
+
 func (g *GameHeader) SetOnCameraButtonPressed(f func()) {
 	g.onCameraButtonPressed = f
 }
 
 func (g *GameHeader) Open() {
 	g.titleButton.disabled = true
+	g.backlogButton.disabled = true
 	g.cameraButton.disabled = true
 	g.isOpening = true
 	g.isClosing = false
@@ -98,6 +113,7 @@ func (g *GameHeader) Open() {
 
 func (g *GameHeader) Close() {
 	g.titleButton.disabled = true
+	g.backlogButton.disabled = true
 	g.cameraButton.disabled = true
 	g.isOpening = false
 	g.isClosing = true
@@ -111,6 +127,7 @@ func (g *GameHeader) Update(paused bool) {
 
 	// TODO: This function should return immediately when input is handled.
 	g.titleButton.HandleInput(g.x, g.y)
+	g.backlogButton.HandleInput(g.x, g.y)
 	g.cameraButton.HandleInput(g.x, g.y)
 
 	if g.isOpening {
@@ -119,6 +136,7 @@ func (g *GameHeader) Update(paused bool) {
 			g.revealRatio = 1.0
 			g.isOpening = false
 			g.titleButton.disabled = false
+			g.backlogButton.disabled = false
 			g.cameraButton.disabled = false
 		}
 	}
@@ -155,5 +173,6 @@ func (g *GameHeader) Draw(screen *ebiten.Image) {
 	screen.DrawImage(g.blackImage, op)
 
 	g.titleButton.DrawAsChild(screen, g.x, g.y-dy)
+	g.backlogButton.DrawAsChild(screen, g.x, g.y-dy)
 	g.cameraButton.DrawAsChild(screen, g.x, g.y-dy)
 }
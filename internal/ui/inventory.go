@@ -168,12 +168,27 @@ func (i *Inventory) SetDisabled(disabled bool) {
 	i.disabled = disabled
 }
 
+func (i *Inventory) isRTL() bool {
+	return lang.IsRTL(lang.Get())
+}
+
+// visualSlotIndex converts the slot index to the position in the scroll bar, and vice versa.
+// The slots are placed from the right in a right-to-left language.
+func (i *Inventory) visualSlotIndex(index int) int {
+	if i.isRTL() {
+		return i.slotCount() - 1 - index
+	}
+	return index
+}
+
 func (i *Inventory) slotIndexAt(x, y int) int {
 	x -= (frameXMargin + frameXPadding) * consts.TileScale
 	y = (y - (frameYPadding * consts.TileScale))
 
 	if x >= 0 && i.y*consts.TileScale <= y && y < (i.y+itemSize)*consts.TileScale {
-		return x / (itemSize * consts.TileScale)
+		if index := x / (itemSize * consts.TileScale); index < i.slotCount() {
+			return i.visualSlotIndex(index)
+		}
 	}
 
 	return -1
@@ -200,6 +215,9 @@ func (i *Inventory) SetOnBackPressed(f func(inventory *Inventory)) {
 }
 
 func (i *Inventory) calcScrollX(pageIndex int) int {
+	if i.isRTL() {
+		pageIndex = i.pageCount() - 1 - pageIndex
+	}
 	return -(pageIndex * (scrollBarWidth - frameXPadding)) * consts.TileScale
 }
 
@@ -268,10 +286,15 @@ func (i *Inventory) Update(texts *data.Texts) {
 		}
 		i.pressStartIndex = -1
 		i.targetPageIndex = i.pageIndex
-		if i.dragX > snapDragX && i.pageIndex > 0 {
+		// Dragging to the right shows the next page in a right-to-left language.
+		dragX := i.dragX
+		if i.isRTL() {
+			dragX = -dragX
+		}
+		if dragX > snapDragX && i.pageIndex > 0 {
 			i.targetPageIndex = i.pageIndex - 1
 		}
-		if i.dragX < -snapDragX && i.pageIndex < i.pageCount()-1 {
+		if dragX < -snapDragX && i.pageIndex < i.pageCount()-1 {
 			i.targetPageIndex = i.pageIndex + 1
 		}
 		i.autoScrolling = true
@@ -292,6 +315,10 @@ func (i *Inventory) Update(texts *data.Texts) {
 		i.backButton.disabled = true
 	}
 
+	if !i.autoScrolling && !i.dragging {
+		// The scroll position depends on the number of the pages in a right-to-left language.
+		i.scrollX = i.calcScrollX(i.pageIndex)
+	}
 	if i.autoScrolling {
 		targetX := i.calcScrollX(i.targetPageIndex)
 		dx := float64(targetX - i.scrollX)
@@ -340,7 +367,7 @@ func (i *Inventory) Draw(screen *ebiten.Image) {
 			itemID = item.ID
 		}
 
-		tx := float64((i.x + frameXMargin + frameXPadding + index*itemSize) + (i.scrollX+i.dragX)/consts.TileScale)
+		tx := float64((i.x + frameXMargin + frameXPadding + i.visualSlotIndex(index)*itemSize) + (i.scrollX+i.dragX)/consts.TileScale)
 		ty := float64(i.y+frameYPadding) + 1
 
 		if tx < float64(i.x) || tx > float64(i.x+frameXMargin+scrollBarWidth) {
@@ -393,7 +420,11 @@ func (i *Inventory) Draw(screen *ebiten.Image) {
 		op := &ebiten.DrawImageOptions{}
 		for index := 0; index < i.pageCount(); index++ {
 			var imagePart *ebiten.Image
-			if index == i.pageIndex {
+			pageIndex := index
+			if i.isRTL() {
+				pageIndex = i.pageCount() - 1 - index
+			}
+			if pageIndex == i.pageIndex {
 				imagePart = i.activeDot
 			} else {
 				imagePart = i.dot
@@ -416,7 +447,9 @@ func (i *Inventory) SetItems(items []*data.Item) {
 	if c >= len(items) || (c < len(i.items) && items[c].ID != i.items[c].ID) {
 		i.pageIndex = 0
 		i.targetPageIndex = 0
-		i.scrollX = 0
+		i.items = items
+		i.scrollX = i.calcScrollX(0)
+		return
 	}
 	i.items = items
 }
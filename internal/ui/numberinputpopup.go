@@ -0,0 +1,311 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ui
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/assets"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/consts"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/lang"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/texts"
+)
+
+const (
+	numberInputDialWidth    = 20
+	numberInputDialSpacing  = 2
+	numberInputDigitHeight  = 20
+	numberInputArrowHeight  = 14
+	numberInputKeypadHeight = 16
+)
+
+type NumberInput interface {
+	Title() string
+	Style() data.InputNumberStyle
+	Digits() int
+	Min() int
+	Max() int
+	Cancelable() bool
+	Value() int
+	Confirm(value int)
+	Cancel()
+}
+
+// NumberInputPopup is a digit dial or a keypad for the input_number command.
+type NumberInputPopup struct {
+	screenHeight int
+	y            int
+	height       int
+	visible      bool
+	numberInput  NumberInput
+	value        int
+
+	// entered is the number of the digits entered with the keypad.
+	// When entered is 0, the next key replaces the initial value.
+	entered int
+
+	titleLabel    *Label
+	digitButtons  []*Button
+	upButtons     []*Button
+	downButtons   []*Button
+	keys          []*Button
+	controls      []*Button
+	deleteButton  *Button
+	cancelButton  *Button
+	confirmButton *Button
+}
+
+func NewNumberInputPopup(screenHeight int) *NumberInputPopup {
+	n := &NumberInputPopup{
+		screenHeight:  screenHeight,
+		titleLabel:    NewLabel(popupMargin+6, 6),
+		deleteButton:  NewButton(0, 0, 0, 0, "system/cancel"),
+		cancelButton:  NewButton(0, 0, 0, 0, "system/cancel"),
+		confirmButton: NewButton(0, 0, 0, 0, "system/click"),
+	}
+	n.deleteButton.SetText("←")
+	n.deleteButton.SetOnPressed(func(_ *Button) {
+		// The initial value is cleared at once.
+		if n.entered == 0 {
+			n.value = 0
+		} else {
+			n.value /= 10
+			n.entered--
+		}
+		n.updateButtons()
+	})
+	n.cancelButton.SetOnPressed(func(_ *Button) {
+		n.Cancel()
+	})
+	n.confirmButton.SetOnPressed(func(_ *Button) {
+		n.numberInput.Confirm(n.value)
+	})
+	return n
+}
+
+func (n *NumberInputPopup) Visible() bool {
+	return n.visible
+}
+
+func (n *NumberInputPopup) Show(numberInput NumberInput) {
+	n.visible = true
+	n.numberInput = numberInput
+	n.value = numberInput.Value()
+	n.entered = 0
+	n.layout()
+}
+
+func (n *NumberInputPopup) Hide() {
+	n.visible = false
+	n.numberInput = nil
+}
+
+// Cancel cancels the input if the input is cancelable.
+func (n *NumberInputPopup) Cancel() {
+	if !n.visible {
+		return
+	}
+	n.numberInput.Cancel()
+}
+
+func pow10(n int) int {
+	v := 1
+	for i := 0; i < n; i++ {
+		v *= 10
+	}
+	return v
+}
+
+// addDigit adds delta to the digit at the given position from the left, wrapping around 0 to 9.
+func (n *NumberInputPopup) addDigit(position int, delta int) {
+	p := pow10(n.numberInput.Digits() - position - 1)
+	d := (n.value / p) % 10
+	nd := (d + delta + 10) % 10
+	n.value += (nd - d) * p
+	n.updateButtons()
+}
+
+func (n *NumberInputPopup) inputDigit(d int) {
+	if n.entered == 0 {
+		n.value = 0
+	}
+	if n.entered >= n.numberInput.Digits() {
+		return
+	}
+	n.value = n.value*10 + d
+	n.entered++
+	n.updateButtons()
+}
+
+func (n *NumberInputPopup) layout() {
+	digits := n.numberInput.Digits()
+	n.digitButtons = nil
+	n.upButtons = nil
+	n.downButtons = nil
+	n.keys = nil
+	n.controls = nil
+
+	y := 24
+	switch n.numberInput.Style() {
+	case data.InputNumberStyleDial:
+		w := (textInputKeysWidth - (digits-1)*numberInputDialSpacing) / digits
+		if w > numberInputDialWidth {
+			w = numberInputDialWidth
+		}
+		x := popupMargin + textInputKeysX + (textInputKeysWidth-(w*digits+(digits-1)*numberInputDialSpacing))/2
+		for i := 0; i < digits; i++ {
+			i := i
+			dx := x + i*(w+numberInputDialSpacing)
+
+			up := NewButton(dx, y, w, numberInputArrowHeight, "system/click")
+			up.SetText("▲")
+			up.SetOnPressed(func(_ *Button) {
+				n.addDigit(i, 1)
+			})
+			n.upButtons = append(n.upButtons, up)
+
+			n.digitButtons = append(n.digitButtons, NewButton(dx, y+numberInputArrowHeight+2, w, numberInputDigitHeight, ""))
+
+			down := NewButton(dx, y+numberInputArrowHeight+numberInputDigitHeight+4, w, numberInputArrowHeight, "system/click")
+			down.SetText("▼")
+			down.SetOnPressed(func(_ *Button) {
+				n.addDigit(i, -1)
+			})
+			n.downButtons = append(n.downButtons, down)
+		}
+		y += numberInputArrowHeight*2 + numberInputDigitHeight + 4
+	case data.InputNumberStyleKeypad:
+		n.digitButtons = append(n.digitButtons, NewButton(popupMargin+6, 22, PopupWidth-12, 18, ""))
+		y = textInputKeysY
+		for _, row := range textInputNumberRows {
+			var buttons []*Button
+			for _, r := range row {
+				b := NewButton(0, 0, 0, 0, "system/click")
+				buttons = append(buttons, b)
+				if r == ' ' {
+					b.Hide()
+					continue
+				}
+				d := int(r - '0')
+				b.SetText(string(r))
+				b.SetOnPressed(func(_ *Button) {
+					n.inputDigit(d)
+				})
+				n.keys = append(n.keys, b)
+			}
+			layoutButtons(buttons, popupMargin+textInputKeysX, y, textInputKeysWidth, numberInputKeypadHeight)
+			y += numberInputKeypadHeight + textInputKeySpacing
+		}
+		n.controls = append(n.controls, n.deleteButton)
+	}
+	n.controls = append(n.controls, n.cancelButton, n.confirmButton)
+	layoutButtons(n.controls, popupMargin+textInputKeysX, y+textInputControlsGap, textInputKeysWidth, textInputKeyHeight+2)
+
+	n.height = y + textInputControlsGap + textInputKeyHeight + 2 + 8
+	n.y = (n.screenHeight - n.height) / 2
+
+	if n.numberInput.Cancelable() {
+		n.cancelButton.Show()
+	} else {
+		n.cancelButton.Hide()
+	}
+	n.updateButtons()
+}
+
+func (n *NumberInputPopup) updateButtons() {
+	if n.numberInput == nil {
+		return
+	}
+
+	text := fmt.Sprintf("%0*d", n.numberInput.Digits(), n.value)
+	if n.numberInput.Style() == data.InputNumberStyleDial {
+		for i, b := range n.digitButtons {
+			b.SetText(text[i : i+1])
+		}
+	} else {
+		n.digitButtons[0].SetText(text)
+	}
+
+	if n.value > 0 {
+		n.deleteButton.Enable()
+	} else {
+		n.deleteButton.Disable()
+	}
+	if n.numberInput.Min() <= n.value && n.value <= n.numberInput.Max() {
+		n.confirmButton.Enable()
+	} else {
+		n.confirmButton.Disable()
+	}
+}
+
+func (n *NumberInputPopup) buttons() []*Button {
+	var bs []*Button
+	bs = append(bs, n.upButtons...)
+	bs = append(bs, n.downButtons...)
+	bs = append(bs, n.keys...)
+	bs = append(bs, n.controls...)
+	return bs
+}
+
+func (n *NumberInputPopup) Update() {
+	if !n.visible {
+		return
+	}
+	l := lang.Get()
+	n.titleLabel.Text = n.numberInput.Title()
+	n.cancelButton.SetText(texts.Text(l, texts.TextIDBack))
+	n.confirmButton.SetText(texts.Text(l, texts.TextIDOK))
+}
+
+func (n *NumberInputPopup) HandleInput(offsetX, offsetY int) bool {
+	if !n.visible {
+		return false
+	}
+	for _, b := range n.buttons() {
+		if b.HandleInput(offsetX, n.y+offsetY) {
+			return true
+		}
+	}
+	// If a popup is visible, do not propagate any input handling to parents.
+	return true
+}
+
+func (n *NumberInputPopup) Draw(screen *ebiten.Image) {
+	if !n.visible {
+		return
+	}
+
+	op := &ebiten.DrawImageOptions{}
+	w, h := shadowImage.Size()
+	sw, sh := screen.Size()
+	op.GeoM.Scale(float64(sw)/float64(w), float64(sh)/float64(h))
+	screen.DrawImage(shadowImage, op)
+
+	geoM := &ebiten.GeoM{}
+	geoM.Translate(popupMargin, float64(n.y))
+	geoM.Scale(consts.TileScale, consts.TileScale)
+	DrawNinePatches(screen, assets.GetImage("system/common/9patch_frame_off.png"), PopupWidth, n.height, geoM, nil)
+
+	n.titleLabel.DrawAsChild(screen, 0, n.y)
+	for _, b := range n.digitButtons {
+		b.DrawAsChild(screen, 0, n.y)
+	}
+	for _, b := range n.buttons() {
+		b.DrawAsChild(screen, 0, n.y)
+	}
+}
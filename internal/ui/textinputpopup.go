@@ -0,0 +1,494 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ui
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hajimehoshi/ebiten"
+	"golang.org/x/text/language"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/assets"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/consts"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/lang"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/texts"
+)
+
+const (
+	TextInputPopupHeight = 148
+
+	textInputKeysX       = 8
+	textInputKeysY       = 46
+	textInputKeysWidth   = PopupWidth - 8
+	textInputKeyHeight   = 14
+	textInputKeySpacing  = 1
+	textInputControlsGap = 3
+)
+
+// A space in the layouts means an empty key.
+var (
+	textInputLatinRows = []string{
+		"ABCDEFGHIJ",
+		"KLMNOPQRST",
+		"UVWXYZ.,-'",
+		"1234567890",
+	}
+	textInputKanaRows = []string{
+		"あかさたなはまやらわ",
+		"いきしちにひみ りを",
+		"うくすつぬふむゆるん",
+		"えけせてねへめ れー",
+		"おこそとのほもよろ ",
+	}
+	textInputNumberRows = []string{
+		"123",
+		"456",
+		"789",
+		" 0 ",
+	}
+)
+
+// The pairs of the base kana and the modified kana.
+const (
+	kanaVoicedPairs     = "かがきぎくぐけげこごさざしじすずせぜそぞただちぢつづてでとどはばひびふぶへべほぼうゔ"
+	kanaSemiVoicedPairs = "はぱひぴふぷへぺほぽ"
+	kanaSmallPairs      = "あぁいぃうぅえぇおぉつっやゃゆゅよょわゎ"
+)
+
+type TextInput interface {
+	Title() string
+	Layout() data.InputTextLayout
+	MaxLength() int
+	Filter() data.InputTextFilter
+	Cancelable() bool
+	Value() string
+	Confirm(value string)
+	Cancel()
+}
+
+type textInputKey struct {
+	button *Button
+	r      rune
+}
+
+// TextInputPopup is an on-screen keyboard for the input_text command.
+type TextInputPopup struct {
+	y         int
+	visible   bool
+	textInput TextInput
+	value     []rune
+	lowercase bool
+	katakana  bool
+
+	titleLabel    *Label
+	fieldButton   *Button
+	keys          []*textInputKey
+	controls      []*Button
+	caseButton    *Button
+	kanaButton    *Button
+	spaceButton   *Button
+	deleteButton  *Button
+	cancelButton  *Button
+	confirmButton *Button
+
+	onRequestNativeInput func(title string, text string, maxLength int, layout data.InputTextLayout)
+}
+
+func NewTextInputPopup(y int) *TextInputPopup {
+	t := &TextInputPopup{
+		y:             y,
+		titleLabel:    NewLabel(popupMargin+6, 6),
+		fieldButton:   NewButton(popupMargin+6, 22, PopupWidth-12, 18, "system/click"),
+		caseButton:    NewButton(0, 0, 0, 0, "system/click"),
+		kanaButton:    NewButton(0, 0, 0, 0, "system/click"),
+		spaceButton:   NewButton(0, 0, 0, 0, "system/click"),
+		deleteButton:  NewButton(0, 0, 0, 0, "system/cancel"),
+		cancelButton:  NewButton(0, 0, 0, 0, "system/cancel"),
+		confirmButton: NewButton(0, 0, 0, 0, "system/click"),
+	}
+	t.kanaButton.SetText("カナ")
+	t.deleteButton.SetText("←")
+
+	t.fieldButton.SetOnPressed(func(_ *Button) {
+		if t.onRequestNativeInput == nil {
+			return
+		}
+		t.onRequestNativeInput(t.textInput.Title(), string(t.value), t.textInput.MaxLength(), t.textInput.Layout())
+	})
+	t.caseButton.SetOnPressed(func(_ *Button) {
+		t.lowercase = !t.lowercase
+		t.updateKeys()
+	})
+	t.kanaButton.SetOnPressed(func(_ *Button) {
+		t.katakana = !t.katakana
+		if t.katakana {
+			t.kanaButton.SetText("かな")
+		} else {
+			t.kanaButton.SetText("カナ")
+		}
+		t.updateKeys()
+	})
+	t.spaceButton.SetOnPressed(func(_ *Button) {
+		if t.textInput.Layout() == data.InputTextLayoutKana {
+			t.input('　')
+			return
+		}
+		t.input(' ')
+	})
+	t.deleteButton.SetOnPressed(func(_ *Button) {
+		if len(t.value) == 0 {
+			return
+		}
+		t.value = t.value[:len(t.value)-1]
+		t.updateKeys()
+	})
+	t.cancelButton.SetOnPressed(func(_ *Button) {
+		t.Cancel()
+	})
+	t.confirmButton.SetOnPressed(func(_ *Button) {
+		t.Confirm()
+	})
+	return t
+}
+
+func (t *TextInputPopup) SetOnRequestNativeInput(f func(title string, text string, maxLength int, layout data.InputTextLayout)) {
+	t.onRequestNativeInput = f
+}
+
+func (t *TextInputPopup) Visible() bool {
+	return t.visible
+}
+
+func (t *TextInputPopup) Show(textInput TextInput) {
+	t.visible = true
+	t.textInput = textInput
+	t.lowercase = false
+	t.katakana = false
+	t.kanaButton.SetText("カナ")
+	t.value = nil
+	t.SetValue(textInput.Value())
+	t.layoutKeys()
+}
+
+func (t *TextInputPopup) Hide() {
+	t.visible = false
+	t.textInput = nil
+}
+
+// Cancel cancels the input if the input is cancelable.
+func (t *TextInputPopup) Cancel() {
+	if !t.visible {
+		return
+	}
+	t.textInput.Cancel()
+}
+
+// Confirm confirms the input if the value is not empty.
+func (t *TextInputPopup) Confirm() {
+	if !t.visible || len(t.value) == 0 {
+		return
+	}
+	t.textInput.Confirm(string(t.value))
+}
+
+// SetValue sets the text e.g. from a native keyboard, and reports whether the whole text is set.
+// The characters that the filter doesn't accept are removed.
+func (t *TextInputPopup) SetValue(value string) bool {
+	t.value = nil
+	all := true
+	for _, r := range value {
+		if !t.accepts(r) {
+			all = false
+			continue
+		}
+		if !t.canInput() {
+			all = false
+			break
+		}
+		t.value = append(t.value, r)
+	}
+	t.updateKeys()
+	return all
+}
+
+func (t *TextInputPopup) accepts(r rune) bool {
+	if r == ' ' || r == '　' {
+		return t.textInput.Filter() == data.InputTextFilterNone
+	}
+	return t.textInput.Filter().Accepts(r)
+}
+
+func (t *TextInputPopup) canInput() bool {
+	if l := t.textInput.MaxLength(); l > 0 && len(t.value) >= l {
+		return false
+	}
+	return true
+}
+
+func (t *TextInputPopup) input(r rune) {
+	if !t.accepts(r) || !t.canInput() {
+		return
+	}
+	t.value = append(t.value, r)
+	t.updateKeys()
+}
+
+func toKatakana(r rune) rune {
+	if 'ぁ' <= r && r <= 'ゖ' {
+		return r + 'ァ' - 'ぁ'
+	}
+	return r
+}
+
+func toHiragana(r rune) rune {
+	if 'ァ' <= r && r <= 'ヶ' {
+		return r - ('ァ' - 'ぁ')
+	}
+	return r
+}
+
+// modifyLastKana toggles the last kana between the base kana and the modified kana e.g. the voiced one.
+func (t *TextInputPopup) modifyLastKana(pairs string) {
+	if len(t.value) == 0 {
+		return
+	}
+	last := t.value[len(t.value)-1]
+	katakana := last != toHiragana(last)
+
+	ps := []rune(pairs)
+	for i := 0; i < len(ps); i += 2 {
+		var r rune
+		switch toHiragana(last) {
+		case ps[i]:
+			r = ps[i+1]
+		case ps[i+1]:
+			r = ps[i]
+		default:
+			continue
+		}
+		if katakana {
+			r = toKatakana(r)
+		}
+		if !t.accepts(r) {
+			return
+		}
+		t.value[len(t.value)-1] = r
+		t.updateKeys()
+		return
+	}
+}
+
+func (t *TextInputPopup) keyRune(r rune) rune {
+	switch t.textInput.Layout() {
+	case data.InputTextLayoutLatin:
+		if t.lowercase {
+			return []rune(strings.ToLower(string(r)))[0]
+		}
+	case data.InputTextLayoutKana:
+		if t.katakana {
+			return toKatakana(r)
+		}
+	}
+	return r
+}
+
+func layoutButtons(buttons []*Button, x, y, width, height int) {
+	n := len(buttons)
+	w := (width - (n-1)*textInputKeySpacing) / n
+	x += (width - (w*n + (n-1)*textInputKeySpacing)) / 2
+	for i, b := range buttons {
+		b.SetX(x + i*(w+textInputKeySpacing))
+		b.SetY(y)
+		b.SetWidth(w)
+		b.height = height
+	}
+}
+
+// layoutKeys creates the keys for the current layout.
+func (t *TextInputPopup) layoutKeys() {
+	var rows []string
+	switch t.textInput.Layout() {
+	case data.InputTextLayoutLatin:
+		rows = textInputLatinRows
+	case data.InputTextLayoutKana:
+		rows = textInputKanaRows
+	case data.InputTextLayoutNumber:
+		rows = textInputNumberRows
+	}
+
+	t.keys = nil
+	y := textInputKeysY
+	for _, row := range rows {
+		var buttons []*Button
+		for _, r := range row {
+			b := NewButton(0, 0, 0, 0, "system/click")
+			buttons = append(buttons, b)
+			if r == ' ' {
+				b.Hide()
+				continue
+			}
+			k := &textInputKey{
+				button: b,
+				r:      r,
+			}
+			b.SetOnPressed(func(_ *Button) {
+				t.input(t.keyRune(k.r))
+			})
+			t.keys = append(t.keys, k)
+		}
+		layoutButtons(buttons, popupMargin+textInputKeysX, y, textInputKeysWidth, textInputKeyHeight)
+		y += textInputKeyHeight + textInputKeySpacing
+	}
+
+	t.controls = nil
+	switch t.textInput.Layout() {
+	case data.InputTextLayoutLatin:
+		t.controls = append(t.controls, t.caseButton, t.spaceButton)
+	case data.InputTextLayoutKana:
+		voiced := NewButton(0, 0, 0, 0, "system/click")
+		voiced.SetText("゛")
+		voiced.SetOnPressed(func(_ *Button) {
+			t.modifyLastKana(kanaVoicedPairs)
+		})
+		semiVoiced := NewButton(0, 0, 0, 0, "system/click")
+		semiVoiced.SetText("゜")
+		semiVoiced.SetOnPressed(func(_ *Button) {
+			t.modifyLastKana(kanaSemiVoicedPairs)
+		})
+		small := NewButton(0, 0, 0, 0, "system/click")
+		small.SetText("小")
+		small.SetOnPressed(func(_ *Button) {
+			t.modifyLastKana(kanaSmallPairs)
+		})
+		t.controls = append(t.controls, t.kanaButton, voiced, semiVoiced, small, t.spaceButton)
+	}
+	t.controls = append(t.controls, t.deleteButton, t.cancelButton, t.confirmButton)
+	layoutButtons(t.controls, popupMargin+textInputKeysX, y+textInputControlsGap, textInputKeysWidth, textInputKeyHeight+2)
+
+	if t.textInput.Cancelable() {
+		t.cancelButton.Show()
+	} else {
+		t.cancelButton.Hide()
+	}
+	t.updateKeys()
+}
+
+// updateKeys updates the texts and the states of the keys.
+func (t *TextInputPopup) updateKeys() {
+	if t.textInput == nil {
+		return
+	}
+	canInput := t.canInput()
+	for _, k := range t.keys {
+		r := t.keyRune(k.r)
+		k.button.SetText(string(r))
+		if canInput && t.accepts(r) {
+			k.button.Enable()
+		} else {
+			k.button.Disable()
+		}
+	}
+	if canInput && t.accepts(' ') {
+		t.spaceButton.Enable()
+	} else {
+		t.spaceButton.Disable()
+	}
+	if len(t.value) > 0 {
+		t.deleteButton.Enable()
+		t.confirmButton.Enable()
+	} else {
+		t.deleteButton.Disable()
+		t.confirmButton.Disable()
+	}
+}
+
+func (t *TextInputPopup) Update() {
+	if !t.visible {
+		return
+	}
+
+	l := lang.Get()
+	t.titleLabel.Text = t.textInput.Title()
+	t.cancelButton.SetText(texts.Text(l, texts.TextIDBack))
+	t.confirmButton.SetText(texts.Text(l, texts.TextIDOK))
+	t.caseButton.SetText(texts.Text(l, texts.TextIDInputCase))
+	if t.textInput.Layout() == data.InputTextLayoutKana {
+		// The kana layout is only for Japanese.
+		t.spaceButton.SetText(texts.Text(language.Japanese, texts.TextIDInputSpace))
+	} else {
+		t.spaceButton.SetText(texts.Text(l, texts.TextIDInputSpace))
+	}
+
+	text := string(t.value)
+	if t.canInput() {
+		text += "_"
+	}
+	if max := t.textInput.MaxLength(); max > 0 {
+		// Pad the text so that the text doesn't move during the input.
+		if n := max + 1 - utf8.RuneCountInString(text); n > 0 {
+			text += strings.Repeat(" ", n)
+		}
+	}
+	t.fieldButton.SetText(text)
+}
+
+func (t *TextInputPopup) HandleInput(offsetX, offsetY int) bool {
+	if !t.visible {
+		return false
+	}
+	if t.fieldButton.HandleInput(offsetX, t.y+offsetY) {
+		return true
+	}
+	for _, k := range t.keys {
+		if k.button.HandleInput(offsetX, t.y+offsetY) {
+			return true
+		}
+	}
+	for _, b := range t.controls {
+		if b.HandleInput(offsetX, t.y+offsetY) {
+			return true
+		}
+	}
+	// If a popup is visible, do not propagate any input handling to parents.
+	return true
+}
+
+func (t *TextInputPopup) Draw(screen *ebiten.Image) {
+	if !t.visible {
+		return
+	}
+
+	op := &ebiten.DrawImageOptions{}
+	w, h := shadowImage.Size()
+	sw, sh := screen.Size()
+	op.GeoM.Scale(float64(sw)/float64(w), float64(sh)/float64(h))
+	screen.DrawImage(shadowImage, op)
+
+	geoM := &ebiten.GeoM{}
+	geoM.Translate(popupMargin, float64(t.y))
+	geoM.Scale(consts.TileScale, consts.TileScale)
+	DrawNinePatches(screen, assets.GetImage("system/common/9patch_frame_off.png"), PopupWidth, TextInputPopupHeight, geoM, nil)
+
+	t.titleLabel.DrawAsChild(screen, 0, t.y)
+	t.fieldButton.DrawAsChild(screen, 0, t.y)
+	for _, k := range t.keys {
+		k.button.DrawAsChild(screen, 0, t.y)
+	}
+	for _, b := range t.controls {
+		b.DrawAsChild(screen, 0, t.y)
+	}
+}
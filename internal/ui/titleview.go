@@ -44,6 +44,9 @@ type TitleView struct {
 	bgImage          *ebiten.Image
 	footerOffset     int
 
+	// rtl indicates whether the UI is laid out for a right-to-left language.
+	rtl bool
+
 	sceneWidth  int
 	sceneHeight int
 
@@ -112,13 +115,23 @@ func (t *TitleView) initUI() {
 	t.startGameButton = NewTextButton(t.startGameButtonX(), h/consts.TileScale-by-32, 120, 20, "system/start")
 	t.removeAdsButton = NewTextButton((w/consts.TileScale-120)/2+20, h/consts.TileScale-by-4, 80, 20, "system/click")
 	t.removeAdsButton.textColor = color.RGBA{0xc8, 0xc8, 0xc8, 0xff}
-	t.settingsButton = NewImageButton(w/consts.TileScale-24, h/consts.TileScale-by, settingsIcon, settingsIcon, "system/click")
+	// The settings button and the more-games button are swapped in a right-to-left language.
+	t.rtl = lang.IsRTL(lang.Get())
+	settingsX, moreGamesX := w/consts.TileScale-24, 12
+	if t.rtl {
+		settingsX, moreGamesX = 12, w/consts.TileScale-24
+	}
+	t.settingsButton = NewImageButton(settingsX, h/consts.TileScale-by, settingsIcon, settingsIcon, "system/click")
 	t.settingsButton.touchExpand = 10
-	t.moregamesButton = NewImageButton(12, h/consts.TileScale-by, moreGamesIcon, moreGamesIcon, "system/click")
+	t.moregamesButton = NewImageButton(moreGamesX, h/consts.TileScale-by, moreGamesIcon, moreGamesIcon, "system/click")
 	t.moregamesButton.touchExpand = 10
 
 	t.quitPopup = NewPopup(h/(2*consts.TileScale)-64, 124)
 	t.quitLabel = NewLabel(16, 8)
+	if t.rtl {
+		t.quitLabel = NewLabel(PopupWidth-16, 8)
+		t.quitLabel.TextAlign = data.TextAlignRight
+	}
 	t.quitYesButton = NewButton((PopupWidth-120)/2, 72, 120, 20, "system/click")
 	t.quitNoButton = NewButton((PopupWidth-120)/2, 96, 120, 20, "system/cancel")
 	t.quitPopup.AddChild(t.quitLabel)
@@ -168,7 +181,7 @@ func (t *TitleView) ResetWaitingRequestID() {
 }
 
 func (t *TitleView) Update(game *data.Game, hasProgress bool, isAdsRemoved bool) error {
-	if !t.initializedUI {
+	if !t.initializedUI || t.rtl != lang.IsRTL(lang.Get()) {
 		t.initUI()
 		t.initializedUI = true
 	}
@@ -196,6 +209,7 @@ func (t *TitleView) Update(game *data.Game, hasProgress bool, isAdsRemoved bool)
 	} else {
 		t.startGameButton.textColor = color.White
 	}
+	t.startGameButton.SetFont(game.System.TitleFont)
 
 	t.removeAdsButton.text = texts.Text(lang.Get(), texts.TextIDRemoveAds)
 	t.quitLabel.Text = texts.Text(lang.Get(), texts.TextIDQuitGame)
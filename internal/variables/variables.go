@@ -30,6 +30,14 @@ type Variables struct {
 	switches     []bool
 	selfSwitches map[string][]bool
 	variables    []int64
+
+	stringVariables []string
+
+	// tableValues are the table values overwritten by the player's inputs.
+	tableValues map[string]string
+
+	// Not dump
+	version int
 }
 
 func (v *Variables) EncodeMsgpack(enc *msgpack.Encoder) error {
@@ -62,6 +70,21 @@ func (v *Variables) EncodeMsgpack(enc *msgpack.Encoder) error {
 	}
 	e.EndArray()
 
+	e.EncodeString("stringVariables")
+	e.BeginArray()
+	for _, val := range v.stringVariables {
+		e.EncodeString(val)
+	}
+	e.EndArray()
+
+	e.EncodeString("tableValues")
+	e.BeginMap()
+	for k, val := range v.tableValues {
+		e.EncodeString(k)
+		e.EncodeString(val)
+	}
+	e.EndMap()
+
 	e.EndMap()
 	return e.Flush()
 }
@@ -104,6 +127,23 @@ func (v *Variables) DecodeMsgpack(dec *msgpack.Decoder) error {
 					v.variables[i] = d.DecodeInt64()
 				}
 			}
+		case "stringVariables":
+			if !d.SkipCodeIfNil() {
+				n := d.DecodeArrayLen()
+				v.stringVariables = make([]string, n)
+				for i := 0; i < n; i++ {
+					v.stringVariables[i] = d.DecodeString()
+				}
+			}
+		case "tableValues":
+			if !d.SkipCodeIfNil() {
+				n := d.DecodeMapLen()
+				v.tableValues = map[string]string{}
+				for i := 0; i < n; i++ {
+					k := d.DecodeString()
+					v.tableValues[k] = d.DecodeString()
+				}
+			}
 		case "innerVariables":
 			d.Skip()
 		}
@@ -171,4 +211,46 @@ func (v *Variables) SetVariableValue(id int, value int64) {
 		v.variables = append(v.variables, zeros...)
 	}
 	v.variables[id] = value
+	v.version++
+}
+
+func (v *Variables) StringVariableValue(id int) string {
+	if len(v.stringVariables) < id+1 {
+		zeros := make([]string, id+1-len(v.stringVariables))
+		v.stringVariables = append(v.stringVariables, zeros...)
+	}
+	return v.stringVariables[id]
+}
+
+func (v *Variables) SetStringVariableValue(id int, value string) {
+	if len(v.stringVariables) < id+1 {
+		zeros := make([]string, id+1-len(v.stringVariables))
+		v.stringVariables = append(v.stringVariables, zeros...)
+	}
+	v.stringVariables[id] = value
+	v.version++
+}
+
+func tableValueKey(tableName string, id int, attrName string) string {
+	return fmt.Sprintf("%s_%d_%s", tableName, id, attrName)
+}
+
+// TableValue returns the table value overwritten by SetTableValue.
+// TableValue returns false when the value is not overwritten.
+func (v *Variables) TableValue(tableName string, id int, attrName string) (string, bool) {
+	val, ok := v.tableValues[tableValueKey(tableName, id, attrName)]
+	return val, ok
+}
+
+func (v *Variables) SetTableValue(tableName string, id int, attrName string, value string) {
+	if v.tableValues == nil {
+		v.tableValues = map[string]string{}
+	}
+	v.tableValues[tableValueKey(tableName, id, attrName)] = value
+	v.version++
+}
+
+// Version returns a number that changes whenever a variable, a string variable or a table value is set.
+func (v *Variables) Version() int {
+	return v.version
 }
@@ -17,6 +17,8 @@ package variables_test
 import (
 	"testing"
 
+	"github.com/vmihailenco/msgpack"
+
 	. "github.com/hajimehoshi/rpgsnack-runtime/internal/variables"
 )
 
@@ -29,3 +31,53 @@ func TestSelfSwitches(t *testing.T) {
 		t.Errorf("SelfSwitchValue(1, 2, 3) got: %v, want: %v", got, want)
 	}
 }
+
+func TestStringVariablesMsgpack(t *testing.T) {
+	v := &Variables{}
+	v.SetStringVariableValue(2, "Alice")
+	v.SetTableValue("characters", 1, "name", "Bob")
+
+	b, err := msgpack.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v2 := &Variables{}
+	if err := msgpack.Unmarshal(b, v2); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := v2.StringVariableValue(2), "Alice"; got != want {
+		t.Errorf("StringVariableValue(2) got: %v, want: %v", got, want)
+	}
+	if got, want := v2.StringVariableValue(0), ""; got != want {
+		t.Errorf("StringVariableValue(0) got: %v, want: %v", got, want)
+	}
+	if got, ok := v2.TableValue("characters", 1, "name"); !ok || got != "Bob" {
+		t.Errorf(`TableValue("characters", 1, "name") got: %v, %v, want: Bob, true`, got, ok)
+	}
+	if _, ok := v2.TableValue("characters", 2, "name"); ok {
+		t.Errorf(`TableValue("characters", 2, "name") must not exist`)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	v := &Variables{}
+	ver := v.Version()
+
+	v.SetSwitchValue(1, true)
+	if got := v.Version(); got != ver {
+		t.Errorf("Version() after SetSwitchValue got: %d, want: %d", got, ver)
+	}
+
+	for _, f := range []func(){
+		func() { v.SetVariableValue(1, 3) },
+		func() { v.SetStringVariableValue(1, "Alice") },
+		func() { v.SetTableValue("characters", 1, "name", "Bob") },
+	} {
+		f()
+		if got := v.Version(); got == ver {
+			t.Errorf("Version() got: %d, want: not %d", got, ver)
+		}
+		ver = v.Version()
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weather
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/assets"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+)
+
+const (
+	fogImageWidth  = 64
+	fogImageHeight = 32
+)
+
+var (
+	rainImage  *ebiten.Image
+	snowImage  *ebiten.Image
+	fogImage   *ebiten.Image
+	leafImage  *ebiten.Image
+	glowImage  *ebiten.Image
+	sandImage  *ebiten.Image
+	whiteImage *ebiten.Image
+)
+
+func init() {
+	rainImage, _ = ebiten.NewImage(1, 20, ebiten.FilterDefault)
+	rainImage.Fill(color.White)
+}
+
+func init() {
+	snowImage = newImageFromAlphas(3, 3, []byte{
+		0x80, 0xff, 0x80,
+		0xff, 0xff, 0xff,
+		0x80, 0xff, 0x80,
+	})
+}
+
+func init() {
+	leafImage = newImageFromAlphas(4, 3, []byte{
+		0x00, 0xc0, 0xff, 0x80,
+		0xc0, 0xff, 0xff, 0xc0,
+		0x80, 0xff, 0xc0, 0x00,
+	})
+}
+
+func init() {
+	fogImage = newBlurredCircleImage(fogImageWidth, fogImageHeight)
+	glowImage = newBlurredCircleImage(7, 7)
+
+	sandImage, _ = ebiten.NewImage(2, 1, ebiten.FilterDefault)
+	sandImage.Fill(color.White)
+
+	whiteImage, _ = ebiten.NewImage(16, 16, ebiten.FilterDefault)
+	whiteImage.Fill(color.White)
+}
+
+func newImageFromAlphas(width, height int, alphas []byte) *ebiten.Image {
+	img, _ := ebiten.NewImage(width, height, ebiten.FilterDefault)
+	pix := make([]byte, len(alphas)*4)
+	for i, v := range alphas {
+		pix[4*i] = v
+		pix[4*i+1] = v
+		pix[4*i+2] = v
+		pix[4*i+3] = v
+	}
+	img.ReplacePixels(pix)
+	return img
+}
+
+// newBlurredCircleImage creates an ellipse image whose alpha decreases toward the edge.
+func newBlurredCircleImage(width, height int) *ebiten.Image {
+	alphas := make([]byte, width*height)
+	for j := 0; j < height; j++ {
+		for i := 0; i < width; i++ {
+			dx := (float64(i)+0.5)/float64(width)*2 - 1
+			dy := (float64(j)+0.5)/float64(height)*2 - 1
+			d := math.Sqrt(dx*dx + dy*dy)
+			if d >= 1 {
+				continue
+			}
+			alphas[j*width+i] = byte(0xff * (1 - d) * (1 - d))
+		}
+	}
+	return newImageFromAlphas(width, height, alphas)
+}
+
+func particleImage(weatherType data.WeatherType, imageName string) *ebiten.Image {
+	if imageName != "" {
+		return assets.GetImage("weather/" + imageName + ".png")
+	}
+	switch weatherType {
+	case data.WeatherTypeRain:
+		return rainImage
+	case data.WeatherTypeSnow:
+		return snowImage
+	case data.WeatherTypeFog:
+		return fogImage
+	case data.WeatherTypeLeaves, data.WeatherTypePetals:
+		return leafImage
+	case data.WeatherTypeFireflies:
+		return glowImage
+	case data.WeatherTypeSandstorm:
+		return sandImage
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weather
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/consts"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+)
+
+const (
+	screenWidth  = consts.MapWidth
+	screenHeight = consts.MapHeight
+
+	fogScale  = 2
+	fogWidth  = fogImageWidth * fogScale
+	fogHeight = fogImageHeight * fogScale
+)
+
+func maxParticleCount(weatherType data.WeatherType) int {
+	switch weatherType {
+	case data.WeatherTypeRain, data.WeatherTypeSnow:
+		return 25
+	case data.WeatherTypeFog:
+		return 6
+	case data.WeatherTypeLeaves, data.WeatherTypePetals, data.WeatherTypeFireflies:
+		return 16
+	case data.WeatherTypeSandstorm:
+		return 60
+	}
+	return 0
+}
+
+func randFloat(r Rand, min, max float64) float64 {
+	return min + (max-min)*float64(r.Intn(10000))/10000
+}
+
+type particle struct {
+	x       float64
+	y       float64
+	vx      float64
+	vy      float64
+	angle   float64
+	phase   float64
+	opacity float64
+	life    int
+	maxLife int
+	alive   bool
+}
+
+// spawn initializes the particle at a random position.
+// If initial is true, the particle can be placed anywhere on the screen
+// instead of the place where particles usually come from.
+func (p *particle) spawn(weatherType data.WeatherType, r Rand, initial bool) {
+	*p = particle{
+		alive: true,
+	}
+	switch weatherType {
+	case data.WeatherTypeRain, data.WeatherTypeSnow:
+		p.x = float64(r.Intn(screenWidth+100) - 100)
+		p.y = float64(r.Intn(screenHeight+200) - 100)
+		p.opacity = float64(160+r.Intn(60)) / 255
+	case data.WeatherTypeFog:
+		p.x = randFloat(r, -fogWidth, screenWidth)
+		p.y = randFloat(r, -fogHeight/2, screenHeight)
+		p.vx = randFloat(r, 0.05, 0.15)
+		p.maxLife = 600 + r.Intn(300)
+		p.life = p.maxLife
+	case data.WeatherTypeLeaves, data.WeatherTypePetals:
+		p.x = randFloat(r, -20, screenWidth+20)
+		if initial {
+			p.y = randFloat(r, -20, screenHeight)
+		} else {
+			p.y = randFloat(r, -20, -5)
+		}
+		p.vy = randFloat(r, 0.3, 0.6)
+		p.phase = randFloat(r, 0, 2*math.Pi)
+		p.angle = randFloat(r, 0, 2*math.Pi)
+		p.opacity = 1
+	case data.WeatherTypeFireflies:
+		p.x = randFloat(r, 0, screenWidth)
+		p.y = randFloat(r, 0, screenHeight)
+		p.vx = randFloat(r, -0.2, 0.2)
+		p.vy = randFloat(r, -0.2, 0.2)
+		p.phase = randFloat(r, 0, 2*math.Pi)
+		p.maxLife = 300 + r.Intn(300)
+		p.life = p.maxLife
+	case data.WeatherTypeSandstorm:
+		p.x = randFloat(r, -10, screenWidth+10)
+		p.y = randFloat(r, 0, screenHeight)
+		p.vx = randFloat(r, 2, 4)
+		p.vy = randFloat(r, -0.2, 0.2)
+		p.opacity = randFloat(r, 0.5, 0.9)
+		p.maxLife = 40 + r.Intn(40)
+		p.life = p.maxLife
+	}
+}
+
+// update updates the particle and reports whether the particle is still alive.
+func (p *particle) update(weatherType data.WeatherType, windX, windY float64, r Rand) bool {
+	switch weatherType {
+	case data.WeatherTypeRain:
+		vx := -2*math.Sin(math.Pi/16) + windX
+		vy := 2*math.Cos(math.Pi/16) + windY
+		p.x += vx
+		p.y += vy
+		p.angle = math.Atan2(vy, vx) - math.Pi/2
+		p.opacity -= 6.0 / 255
+		return p.opacity > 0
+	case data.WeatherTypeSnow:
+		p.x += -1*math.Sin(math.Pi/16) + windX
+		p.y += 1*math.Cos(math.Pi/16) + windY
+		p.opacity -= 3.0 / 255
+		return p.opacity > 0
+	case data.WeatherTypeFog:
+		p.x += p.vx + windX/2
+		p.y += windY / 2
+		if p.x > screenWidth {
+			p.x -= screenWidth + fogWidth
+		}
+		if p.x < -fogWidth {
+			p.x += screenWidth + fogWidth
+		}
+		p.life--
+		return p.life > 0
+	case data.WeatherTypeLeaves, data.WeatherTypePetals:
+		p.phase += 0.05
+		p.x += math.Sin(p.phase)*0.5 + windX
+		p.y += p.vy + windY
+		p.angle += 0.03 * math.Cos(p.phase)
+		return -30 <= p.x && p.x <= screenWidth+30 && p.y <= screenHeight+10
+	case data.WeatherTypeFireflies:
+		p.vx = math.Max(-0.3, math.Min(0.3, p.vx+randFloat(r, -0.02, 0.02)))
+		p.vy = math.Max(-0.3, math.Min(0.3, p.vy+randFloat(r, -0.02, 0.02)))
+		p.x += p.vx + windX/5
+		p.y += p.vy + windY/5
+		p.phase += 0.08
+		p.life--
+		return p.life > 0
+	case data.WeatherTypeSandstorm:
+		p.x += p.vx + windX*3
+		p.y += p.vy + windY*3
+		p.life--
+		return p.life > 0
+	}
+	return false
+}
+
+// lifeRate returns a value that goes 0 to 1 and back to 0 during the particle's life.
+func (p *particle) lifeRate() float64 {
+	if p.maxLife == 0 {
+		return 1
+	}
+	return math.Sin(math.Pi * float64(p.life) / float64(p.maxLife))
+}
+
+func (p *particle) alpha(weatherType data.WeatherType) float64 {
+	switch weatherType {
+	case data.WeatherTypeFog:
+		return 0.35 * p.lifeRate()
+	case data.WeatherTypeFireflies:
+		return (0.5 + 0.5*math.Sin(p.phase)) * p.lifeRate()
+	case data.WeatherTypeSandstorm:
+		return p.opacity * p.lifeRate()
+	}
+	return p.opacity
+}
@@ -15,127 +15,331 @@
 package weather
 
 import (
-	"image/color"
+	"fmt"
 	"math"
 	"math/rand"
+	"time"
 
 	"github.com/hajimehoshi/ebiten"
+	"github.com/vmihailenco/msgpack"
 
-	"github.com/hajimehoshi/rpgsnack-runtime/internal/consts"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/easymsgpack"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/interpolation"
 )
 
-var (
-	rainImage *ebiten.Image
-	snowImage *ebiten.Image
-)
+type Rand interface {
+	Intn(n int) int
+}
+
+func generateDefaultRand() Rand {
+	return rand.New(rand.NewSource(time.Now().UnixNano()))
+}
+
+// layer is a set of particles of one weather type.
+type layer struct {
+	weatherType data.WeatherType
+	imageName   string
+	intensity   *interpolation.I
+
+	// Not dumped
+	particles []*particle
+	started   bool
+}
+
+func (l *layer) EncodeMsgpack(enc *msgpack.Encoder) error {
+	e := easymsgpack.NewEncoder(enc)
+	e.BeginMap()
 
-func init() {
-	rainImage, _ = ebiten.NewImage(1, 20, ebiten.FilterDefault)
-	rainImage.Fill(color.White)
+	e.EncodeString("weatherType")
+	e.EncodeString(string(l.weatherType))
+
+	e.EncodeString("imageName")
+	e.EncodeString(l.imageName)
+
+	e.EncodeString("intensity")
+	e.EncodeInterface(l.intensity)
+
+	e.EndMap()
+	return e.Flush()
 }
 
-func init() {
-	snowImage, _ = ebiten.NewImage(3, 3, ebiten.FilterDefault)
-	alphas := []byte{
-		0x80, 0xff, 0x80,
-		0xff, 0xff, 0xff,
-		0x80, 0xff, 0x80,
+func (l *layer) DecodeMsgpack(dec *msgpack.Decoder) error {
+	d := easymsgpack.NewDecoder(dec)
+
+	n := d.DecodeMapLen()
+	for i := 0; i < n; i++ {
+		switch k := d.DecodeString(); k {
+		case "weatherType":
+			l.weatherType = data.WeatherType(d.DecodeString())
+		case "imageName":
+			l.imageName = d.DecodeString()
+		case "intensity":
+			l.intensity = &interpolation.I{}
+			d.DecodeInterface(l.intensity)
+		}
 	}
-	pix := make([]byte, len(alphas)*4)
-	for i, v := range alphas {
-		pix[4*i] = v
-		pix[4*i+1] = v
-		pix[4*i+2] = v
-		pix[4*i+3] = v
+
+	if err := d.Error(); err != nil {
+		return fmt.Errorf("weather: layer.DecodeMsgpack failed: %v", err)
 	}
-	snowImage.ReplacePixels(pix)
+	return nil
 }
 
-type sprite struct {
-	weatherType data.WeatherType
-	x           float64
-	y           float64
-	opacity     int
+func (l *layer) isFadingOut() bool {
+	return l.intensity.Dst() == 0
+}
+
+func (l *layer) finished() bool {
+	return l.isFadingOut() && !l.intensity.IsChanging()
 }
 
-func (s *sprite) update() {
-	const (
-		screenWidth  = consts.MapWidth
-		screenHeight = consts.MapHeight
-	)
-	switch s.weatherType {
-	case data.WeatherTypeRain:
-		s.x -= 2 * math.Sin(math.Pi/16)
-		s.y += 2 * math.Cos(math.Pi/16)
-		s.opacity -= 6
-	case data.WeatherTypeSnow:
-		s.x -= 1 * math.Sin(math.Pi/16)
-		s.y += 1 * math.Cos(math.Pi/16)
-		s.opacity -= 3
+func (l *layer) update(windX, windY float64, r Rand) {
+	l.intensity.Update()
+
+	n := int(math.Ceil(float64(maxParticleCount(l.weatherType)) * l.intensity.Current()))
+	for len(l.particles) < n {
+		p := &particle{}
+		p.spawn(l.weatherType, r, !l.started)
+		l.particles = append(l.particles, p)
 	}
+	l.started = true
 
-	if s.opacity <= 0 {
-		s.x = float64(rand.Intn(screenWidth+100) - 100)
-		s.y = float64(rand.Intn(screenHeight+200) - 100)
-		s.opacity = 160 + rand.Intn(60)
+	for i, p := range l.particles {
+		if !p.alive {
+			// Particles beyond the current intensity are not spawned again.
+			if i < n {
+				p.spawn(l.weatherType, r, false)
+			}
+			continue
+		}
+		p.alive = p.update(l.weatherType, windX, windY, r)
 	}
 }
 
-func (s *sprite) draw(screen *ebiten.Image) {
-	var img *ebiten.Image
-	switch s.weatherType {
-	case data.WeatherTypeRain:
-		img = rainImage
-	case data.WeatherTypeSnow:
-		img = snowImage
+func (l *layer) colorScale() (float64, float64, float64) {
+	if l.imageName != "" {
+		return 1, 1, 1
+	}
+	switch l.weatherType {
+	case data.WeatherTypeLeaves:
+		return 0.85, 0.5, 0.2
+	case data.WeatherTypePetals:
+		return 1, 0.75, 0.85
+	case data.WeatherTypeFireflies:
+		return 0.85, 1, 0.45
+	case data.WeatherTypeSandstorm:
+		return 0.85, 0.7, 0.45
+	}
+	return 1, 1, 1
+}
+
+func (l *layer) draw(screen *ebiten.Image) {
+	// Fading layers become transparent so that long-living particles like leaves don't disappear suddenly.
+	layerAlpha := 1.0
+	if l.isFadingOut() {
+		layerAlpha = math.Min(1, l.intensity.Current())
+	}
+	cr, cg, cb := l.colorScale()
+
+	switch l.weatherType {
+	case data.WeatherTypeFog, data.WeatherTypeSandstorm:
+		sw, sh := screen.Size()
+		w, h := whiteImage.Size()
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Scale(float64(sw)/float64(w), float64(sh)/float64(h))
+		op.ColorM.Scale(cr, cg, cb, 0.2*math.Min(1, l.intensity.Current()))
+		screen.DrawImage(whiteImage, op)
+	}
+
+	img := particleImage(l.weatherType, l.imageName)
+	if img == nil {
+		return
+	}
+	w, h := img.Size()
+	scale := 1.0
+	if l.weatherType == data.WeatherTypeFog {
+		scale = fogScale
 	}
 
-	op := &ebiten.DrawImageOptions{}
-	if s.weatherType == data.WeatherTypeRain {
-		op.GeoM.Rotate(math.Pi / 16)
+	for _, p := range l.particles {
+		if !p.alive {
+			continue
+		}
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(-float64(w)/2, -float64(h)/2)
+		op.GeoM.Scale(scale, scale)
+		op.GeoM.Rotate(p.angle)
+		op.GeoM.Translate(p.x+float64(w)*scale/2, p.y+float64(h)*scale/2)
+		op.ColorM.Scale(cr, cg, cb, p.alpha(l.weatherType)*layerAlpha)
+		op.Filter = ebiten.FilterLinear
+		if l.weatherType == data.WeatherTypeFireflies {
+			op.CompositeMode = ebiten.CompositeModeLighter
+		}
+		screen.DrawImage(img, op)
 	}
-	op.GeoM.Translate(s.x, s.y)
-	op.ColorM.Scale(1, 1, 1, float64(s.opacity)/255)
-	op.Filter = ebiten.FilterLinear
-	screen.DrawImage(img, op)
 }
 
 type Weather struct {
-	weatherType data.WeatherType
-	sprites     []*sprite
+	layers []*layer
+	windX  *interpolation.I
+	windY  *interpolation.I
+
+	// Not dumped
+	rand Rand
+}
+
+func New() *Weather {
+	return &Weather{
+		windX: interpolation.New(0),
+		windY: interpolation.New(0),
+		rand:  generateDefaultRand(),
+	}
+}
+
+func (w *Weather) EncodeMsgpack(enc *msgpack.Encoder) error {
+	e := easymsgpack.NewEncoder(enc)
+	e.BeginMap()
+
+	e.EncodeString("layers")
+	e.BeginArray()
+	for _, l := range w.layers {
+		e.EncodeInterface(l)
+	}
+	e.EndArray()
+
+	e.EncodeString("windX")
+	e.EncodeInterface(w.windX)
+
+	e.EncodeString("windY")
+	e.EncodeInterface(w.windY)
+
+	e.EndMap()
+	return e.Flush()
 }
 
-func New(weatherType data.WeatherType) *Weather {
-	const spriteNum = 25
+func (w *Weather) DecodeMsgpack(dec *msgpack.Decoder) error {
+	d := easymsgpack.NewDecoder(dec)
 
-	sprites := make([]*sprite, spriteNum)
-	for i := range sprites {
-		sprites[i] = &sprite{
-			weatherType: weatherType,
+	n := d.DecodeMapLen()
+	for i := 0; i < n; i++ {
+		switch k := d.DecodeString(); k {
+		case "layers":
+			if !d.SkipCodeIfNil() {
+				n := d.DecodeArrayLen()
+				w.layers = make([]*layer, n)
+				for i := 0; i < n; i++ {
+					w.layers[i] = &layer{}
+					d.DecodeInterface(w.layers[i])
+				}
+			}
+		case "windX":
+			w.windX = &interpolation.I{}
+			d.DecodeInterface(w.windX)
+		case "windY":
+			w.windY = &interpolation.I{}
+			d.DecodeInterface(w.windY)
 		}
 	}
+	w.rand = generateDefaultRand()
 
-	return &Weather{
+	if err := d.Error(); err != nil {
+		return fmt.Errorf("weather: Weather.DecodeMsgpack failed: %v", err)
+	}
+	return nil
+}
+
+func (w *Weather) SetRandomForTesting(r Rand) {
+	w.rand = r
+}
+
+// ParticlePositionsForTesting returns the positions of the living particles.
+func (w *Weather) ParticlePositionsForTesting() [][2]float64 {
+	var ps [][2]float64
+	for _, l := range w.layers {
+		for _, p := range l.particles {
+			if p.alive {
+				ps = append(ps, [2]float64{p.x, p.y})
+			}
+		}
+	}
+	return ps
+}
+
+// Set changes the weather in count frames.
+//
+// intensity is the density of the particles (1 is the default).
+// windDirection is in radians, and windStrength is in pixels per frame.
+// imageName is a custom particle image name in images/weather. The default image is used when imageName is empty.
+//
+// When the weather type changes, the current particles fade out while the new ones fade in.
+func (w *Weather) Set(weatherType data.WeatherType, intensity float64, windDirection, windStrength float64, imageName string, count int) {
+	w.windX.Set(windStrength*math.Cos(windDirection), count)
+	w.windY.Set(windStrength*math.Sin(windDirection), count)
+
+	if len(w.layers) > 0 {
+		l := w.layers[len(w.layers)-1]
+		if l.weatherType == weatherType && l.imageName == imageName && !l.isFadingOut() {
+			l.intensity.Set(intensity, count)
+			return
+		}
+	}
+
+	if count == 0 {
+		w.layers = nil
+	}
+	for _, l := range w.layers {
+		l.intensity.Set(0, count)
+	}
+	if weatherType == data.WeatherTypeNone {
+		return
+	}
+
+	l := &layer{
 		weatherType: weatherType,
-		sprites:     sprites,
+		imageName:   imageName,
+		intensity:   interpolation.New(0),
 	}
+	l.intensity.Set(intensity, count)
+	w.layers = append(w.layers, l)
+}
+
+// WeatherType returns the current weather type.
+// WeatherType returns data.WeatherTypeNone when the weather is fading out.
+func (w *Weather) WeatherType() data.WeatherType {
+	if w == nil || len(w.layers) == 0 {
+		return data.WeatherTypeNone
+	}
+	l := w.layers[len(w.layers)-1]
+	if l.isFadingOut() {
+		return data.WeatherTypeNone
+	}
+	return l.weatherType
 }
 
 func (w *Weather) Update() {
 	if w == nil {
 		return
 	}
-	for _, s := range w.sprites {
-		s.update()
+	w.windX.Update()
+	w.windY.Update()
+
+	layers := w.layers[:0]
+	for _, l := range w.layers {
+		l.update(w.windX.Current(), w.windY.Current(), w.rand)
+		if l.finished() {
+			continue
+		}
+		layers = append(layers, l)
 	}
+	w.layers = layers
 }
 
 func (w *Weather) Draw(screen *ebiten.Image) {
 	if w == nil {
 		return
 	}
-	for _, s := range w.sprites {
-		s.draw(screen)
+	for _, l := range w.layers {
+		l.draw(screen)
 	}
 }
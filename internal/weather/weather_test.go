@@ -0,0 +1,99 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weather_test
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/vmihailenco/msgpack"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+	. "github.com/hajimehoshi/rpgsnack-runtime/internal/weather"
+)
+
+func TestWeatherDeterministic(t *testing.T) {
+	types := []data.WeatherType{
+		data.WeatherTypeRain,
+		data.WeatherTypeSnow,
+		data.WeatherTypeFog,
+		data.WeatherTypeLeaves,
+		data.WeatherTypePetals,
+		data.WeatherTypeFireflies,
+		data.WeatherTypeSandstorm,
+	}
+	for _, wt := range types {
+		w1 := New()
+		w1.SetRandomForTesting(rand.New(rand.NewSource(1)))
+		w1.Set(wt, 1, 0, 0.5, "", 30)
+
+		w2 := New()
+		w2.SetRandomForTesting(rand.New(rand.NewSource(1)))
+		w2.Set(wt, 1, 0, 0.5, "", 30)
+
+		for i := 0; i < 120; i++ {
+			w1.Update()
+			w2.Update()
+		}
+		got := w1.ParticlePositionsForTesting()
+		want := w2.ParticlePositionsForTesting()
+		if len(got) == 0 {
+			t.Errorf("%s: no particles", wt)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: particles differ with the same seed", wt)
+		}
+	}
+}
+
+func TestWeatherTransition(t *testing.T) {
+	w := New()
+	w.SetRandomForTesting(rand.New(rand.NewSource(1)))
+	w.Set(data.WeatherTypeRain, 1, 0, 0, "", 0)
+	w.Set(data.WeatherTypeSnow, 1, 0, 0, "", 60)
+	if got, want := w.WeatherType(), data.WeatherTypeSnow; got != want {
+		t.Errorf("WeatherType(): got: %s, want: %s", got, want)
+	}
+
+	w.Set(data.WeatherTypeNone, 1, 0, 0, "", 60)
+	if got, want := w.WeatherType(), data.WeatherTypeNone; got != want {
+		t.Errorf("WeatherType(): got: %s, want: %s", got, want)
+	}
+	for i := 0; i < 60; i++ {
+		w.Update()
+	}
+	if got := len(w.ParticlePositionsForTesting()); got != 0 {
+		t.Errorf("len(ParticlePositionsForTesting()): got: %d, want: 0", got)
+	}
+}
+
+func TestMarshalWeather(t *testing.T) {
+	w := New()
+	w.Set(data.WeatherTypeFog, 0.5, 0, 1, "", 60)
+	w.Update()
+
+	b, err := msgpack.Marshal(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var w2 *Weather
+	if err := msgpack.Unmarshal(b, &w2); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := w2.WeatherType(), data.WeatherTypeFog; got != want {
+		t.Errorf("WeatherType(): got: %s, want: %s", got, want)
+	}
+}
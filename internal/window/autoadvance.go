@@ -0,0 +1,121 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package window
+
+import (
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/consts"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/scene"
+)
+
+const (
+	autoModeBaseFrames    = 60
+	autoModeFramesPerRune = 3
+
+	// skipModeWaitingFrames is the number of frames to show a read message in the skip mode.
+	skipModeWaitingFrames = 4
+)
+
+func (w *Windows) markRead(sceneManager *scene.Manager, contentID data.UUID) bool {
+	if contentID == (data.UUID{}) {
+		return false
+	}
+	read := sceneManager.IsTextRead(contentID)
+	sceneManager.MarkTextRead(contentID)
+	return read
+}
+
+// updateAutoAdvance updates the number of frames while the shown messages wait for the user's input.
+func (w *Windows) updateAutoAdvance() {
+	if w.isOpened(0) && !w.IsAnimating(0) && !w.IsBusyWithChoosing() {
+		w.autoWaitingCount++
+		return
+	}
+	w.autoWaitingCount = 0
+}
+
+// autoWaitingFrames returns the number of frames to wait for in the auto mode.
+func (w *Windows) autoWaitingFrames(interpreterID consts.InterpreterID) int {
+	var contents []string
+	for _, b := range w.balloons {
+		if b == nil || !b.isOpened() {
+			continue
+		}
+		if interpreterID > 0 && b.interpreterID != interpreterID {
+			continue
+		}
+		contents = append(contents, b.content)
+	}
+	if w.banner != nil && w.banner.isOpened() && (interpreterID == 0 || w.banner.interpreterID == interpreterID) {
+		contents = append(contents, w.banner.content)
+	}
+	return autoWaitingFramesForContents(contents)
+}
+
+// autoWaitingFramesForContents returns the number of frames to wait for the shown contents in the auto mode.
+// The longer the text is, the longer the frames are.
+func autoWaitingFramesForContents(contents []string) int {
+	n := 0
+	for _, c := range contents {
+		if l := len([]rune(plainContent(c))); n < l {
+			n = l
+		}
+	}
+	return autoModeBaseFrames + n*autoModeFramesPerRune
+}
+
+func AutoWaitingFramesForTesting(contents []string) int {
+	return autoWaitingFramesForContents(contents)
+}
+
+// isRead reports whether all the shown messages have been read before.
+func (w *Windows) isRead(interpreterID consts.InterpreterID) bool {
+	found := false
+	for _, b := range w.balloons {
+		if b == nil || !b.isOpened() {
+			continue
+		}
+		if interpreterID > 0 && b.interpreterID != interpreterID {
+			continue
+		}
+		if !b.read {
+			return false
+		}
+		found = true
+	}
+	if w.banner != nil && w.banner.isOpened() && (interpreterID == 0 || w.banner.interpreterID == interpreterID) {
+		if !w.banner.read {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+func (w *Windows) canProceedAutomatically(interpreterID consts.InterpreterID) bool {
+	if w.IsBusyWithChoosing() {
+		return false
+	}
+	if w.IsAnimating(interpreterID) {
+		return false
+	}
+	if w.skipMode && w.isRead(interpreterID) && w.autoWaitingCount >= skipModeWaitingFrames {
+		return true
+	}
+	if w.autoMode && w.autoWaitingCount >= w.autoWaitingFrames(interpreterID) {
+		return true
+	}
+	return false
+}
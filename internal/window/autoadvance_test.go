@@ -0,0 +1,58 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package window_test
+
+import (
+	"testing"
+
+	. "github.com/hajimehoshi/rpgsnack-runtime/internal/window"
+)
+
+func TestAutoWaitingFrames(t *testing.T) {
+	cases := []struct {
+		Contents []string
+		Frames   int
+	}{
+		{
+			Contents: nil,
+			Frames:   60,
+		},
+		{
+			Contents: []string{"Hello"},
+			Frames:   75,
+		},
+		{
+			// The longest content is used.
+			Contents: []string{"Hi", "Hello, world"},
+			Frames:   96,
+		},
+		{
+			// Markup tags and control characters are not counted.
+			Contents: []string{`\c[#ff0000]Hello\c[/]\.`},
+			Frames:   75,
+		},
+		{
+			// A ruby is counted as one unit.
+			Contents: []string{`\r[漢字|かんじ]です`},
+			Frames:   69,
+		},
+	}
+	for _, c := range cases {
+		got := AutoWaitingFramesForTesting(c.Contents)
+		if got != c.Frames {
+			t.Errorf("AutoWaitingFramesForTesting(%q): got: %d, want: %d", c.Contents, got, c.Frames)
+		}
+	}
+}
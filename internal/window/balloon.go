@@ -37,6 +37,11 @@ const (
 	balloonArrowWidth  = 6
 	balloonArrowHeight = 5
 	balloonMinWidth    = 24
+	balloonMaxWidth    = consts.MapWidth - 16
+
+	// balloonFaceMaxHeight is the maximum height of a face in a balloon.
+	// A larger face is scaled down.
+	balloonFaceMaxHeight = 32
 )
 
 type balloon struct {
@@ -58,7 +63,12 @@ type balloon struct {
 	messageStyle   *data.MessageStyle
 	typingEffect   *typingEffect
 	checked        bool
+	voice          *voice
+	speaker        *speaker
 
+	// Not dump
+	read      bool
+	dimmed    bool
 	offscreen *ebiten.Image
 }
 
@@ -120,6 +130,12 @@ func (b *balloon) EncodeMsgpack(enc *msgpack.Encoder) error {
 	e.EncodeString("checked")
 	e.EncodeBool(b.checked)
 
+	e.EncodeString("voice")
+	e.EncodeInterface(b.voice)
+
+	e.EncodeString("speaker")
+	e.EncodeInterface(b.speaker)
+
 	e.EndMap()
 	return e.Flush()
 }
@@ -171,11 +187,25 @@ func (b *balloon) DecodeMsgpack(dec *msgpack.Decoder) error {
 			}
 		case "checked":
 			b.checked = d.DecodeBool()
+		case "voice":
+			if !d.SkipCodeIfNil() {
+				b.voice = &voice{}
+				d.DecodeInterface(b.voice)
+			}
+		case "speaker":
+			if !d.SkipCodeIfNil() {
+				b.speaker = &speaker{}
+				d.DecodeInterface(b.speaker)
+			}
 		}
 	}
 	if err := d.Error(); err != nil {
 		return fmt.Errorf("window: balloon.DecodeMsgpack failed: %v", err)
 	}
+	// Don't replay the voice that was already played before saving.
+	if b.voice != nil {
+		b.voice.played = b.opened || b.closingCount > 0
+	}
 	return nil
 }
 
@@ -212,14 +242,32 @@ func balloonMargin(balloonType data.BalloonType) (int, int) {
 	return 4, 4
 }
 
-func balloonSizeFromContent(content string, balloonType data.BalloonType) (int, int, int, int) {
+// balloonMaxTextWidth returns the maximum width of the text in the same unit as font.MeasureSize.
+func balloonMaxTextWidth(balloonType data.BalloonType, faceWidth int) int {
+	mx, _ := balloonMargin(balloonType)
+	w := balloonMaxWidth - 2*mx
+	if faceWidth > 0 {
+		w -= faceWidth + mx
+	}
+	return w * consts.TileScale / consts.TextScale
+}
+
+// balloonSizeFromContent returns the balloon size and the content offset.
+// faceWidth and faceHeight are the face size, that are 0 when there is no face.
+func balloonSizeFromContent(content string, fontName string, balloonType data.BalloonType, faceWidth, faceHeight int, faceRight bool) (int, int, int, int) {
 	// content is already parsed here.
-	tw, th := font.MeasureSize(content)
+	tw, th := measureContent(content, fontName)
 	tw = tw * consts.TextScale / consts.TileScale
 	th = th * consts.TextScale / consts.TileScale
 	mx, my := balloonMargin(balloonType)
 	w := tw + 2*mx
 	h := th + 2*my
+	if faceWidth > 0 {
+		w += faceWidth + mx
+		if h < faceHeight+2*my {
+			h = faceHeight + 2*my
+		}
+	}
 	s := balloonPartSize(balloonType)
 	w = ((w + (s - 1)) / s) * s
 	h = ((h + (s - 1)) / s) * s
@@ -228,11 +276,14 @@ func balloonSizeFromContent(content string, balloonType data.BalloonType) (int,
 		contentOffsetX = (balloonMinWidth - w) / 2
 		w = balloonMinWidth
 	}
+	if faceWidth > 0 && !faceRight {
+		contentOffsetX += faceWidth + mx
+	}
 	contentOffsetY := ((h - 2*my) - th) / 2
 	return w, h, contentOffsetX, contentOffsetY
 }
 
-func newBalloonWithArrow(contentID data.UUID, content string, balloonType data.BalloonType, eventID int, interpreterID consts.InterpreterID, messageStyle *data.MessageStyle) *balloon {
+func newBalloonWithArrow(contentID data.UUID, content string, balloonType data.BalloonType, eventID int, interpreterID consts.InterpreterID, messageStyle *data.MessageStyle, voice *voice, speaker *speaker) *balloon {
 	font.DrawTextToScratchPad(content, consts.TextScale, lang.Get())
 
 	b := &balloon{
@@ -242,6 +293,8 @@ func newBalloonWithArrow(contentID data.UUID, content string, balloonType data.B
 		eventID:       eventID,
 		balloonType:   balloonType,
 		messageStyle:  messageStyle,
+		voice:         voice,
+		speaker:       speaker,
 	}
 	b.setContent(content, false)
 	return b
@@ -254,7 +307,9 @@ func (b *balloon) overwriteContent(content string) {
 func (b *balloon) setContent(content string, overwrite bool) {
 	b.content = content
 	if b.hasArrow {
-		w, h, contentOffsetX, contentOffsetY := balloonSizeFromContent(visibleContent(b.content), b.balloonType)
+		fw, fh, _ := b.speaker.faceSize(balloonFaceMaxHeight)
+		b.content = wrapContent(content, balloonMaxTextWidth(b.balloonType, fw), b.messageStyle.Font)
+		w, h, contentOffsetX, contentOffsetY := balloonSizeFromContent(visibleContent(b.content), b.messageStyle.Font, b.balloonType, fw, fh, b.speaker.isFaceRight())
 		b.width = w
 		b.height = h
 		b.contentOffsetX = contentOffsetX
@@ -268,6 +323,13 @@ func (b *balloon) setContent(content string, overwrite bool) {
 
 func (b *balloon) trySkipTypingAnim() {
 	b.typingEffect.trySkipAnim()
+	b.voice.skip()
+}
+
+// skipReadText skips the typing effect and the voice regardless of the voice's wait flag.
+func (b *balloon) skipReadText() {
+	b.typingEffect.trySkipAnim()
+	b.voice.stop()
 }
 
 func (b *balloon) arrowPosition(screenWidth int, character *character.Character) (int, int) {
@@ -306,7 +368,7 @@ func (b *balloon) isOpened() bool {
 }
 
 func (b *balloon) isAnimating() bool {
-	return b.openingCount > 0 || b.closingCount > 0 || b.typingEffect.isAnimating()
+	return b.openingCount > 0 || b.closingCount > 0 || b.typingEffect.isAnimating() || b.voice.isWaiting()
 }
 
 func (b *balloon) open() {
@@ -317,9 +379,11 @@ func (b *balloon) open() {
 
 func (b *balloon) close() {
 	b.closingCount = balloonMaxCount
+	b.voice.stop()
 }
 
 func (b *balloon) closeImmediately() {
+	b.voice.stop()
 	b.opened = false
 	b.openingCount = 0
 	b.closingCount = 0
@@ -353,6 +417,7 @@ func (b *balloon) update(character *character.Character) {
 		if b.openingCount == 0 {
 			b.opened = true
 			b.playCharacterAnim(character)
+			b.voice.play()
 		}
 	}
 	if b.opened && b.typingEffect.isAnimating() {
@@ -483,7 +548,18 @@ func (b *balloon) draw(screen *ebiten.Image, character *character.Character, off
 		if b.checked {
 			op.ColorM.Scale(1.0, 1.0, 0.5, 1.0)
 		}
+		if b.dimmed {
+			op.ColorM.Scale(0.6, 0.6, 0.6, 1.0)
+		}
 		screen.DrawImage(b.offscreen, op)
+		if fw, fh, scale := b.speaker.faceSize(balloonFaceMaxHeight); fw > 0 {
+			mx, _ := b.margin()
+			fx := tx + mx
+			if b.speaker.isFaceRight() {
+				fx = tx + b.width - mx - fw
+			}
+			b.speaker.drawFace(screen, fx, ty+(b.height-fh)/2, scale, g, 1)
+		}
 		if b.checked {
 			checkOp := &ebiten.DrawImageOptions{}
 			checkOp.GeoM.Translate(float64(tx), float64(ty))
@@ -524,11 +600,27 @@ func (b *balloon) draw(screen *ebiten.Image, character *character.Character, off
 	}
 	if b.opened {
 		x, y := b.position(sw, character)
+		if b.speaker.hasName() {
+			nx := (x + int(dx)) * consts.TileScale
+			if b.speaker.isFaceRight() {
+				nx = (x + b.width + int(dx)) * consts.TileScale
+			}
+			b.speaker.drawName(screen, nx, (y+int(dy))*consts.TileScale, b.speaker.isFaceRight())
+		}
 		mx, my := b.margin()
 		x = (x + mx + b.contentOffsetX) * consts.TileScale
 		y = (y + my + b.contentOffsetY) * consts.TileScale
 		x += int(dx * consts.TileScale)
 		y += int(dy * consts.TileScale)
-		b.typingEffect.draw(screen, x, y, consts.TextScale, data.TextAlignLeft, color.Black, nil, nil)
+		textAlign := localizedTextAlign(data.TextAlignLeft)
+		if textAlign == data.TextAlignRight {
+			if b.hasArrow {
+				tw, _ := measureContent(visibleContent(b.content), b.messageStyle.Font)
+				x += tw * consts.TextScale
+			} else {
+				x += (b.width - 2*mx) * consts.TileScale
+			}
+		}
+		b.typingEffect.draw(screen, x, y, consts.TextScale, textAlign, color.Black, nil, nil, b.messageStyle.Font)
 	}
 }
@@ -52,6 +52,11 @@ type banner struct {
 	messageStyle  *data.MessageStyle
 	typingEffect  *typingEffect
 	eventID       int
+	voice         *voice
+	speaker       *speaker
+
+	// Not dump
+	read bool
 }
 
 func (b *banner) EncodeMsgpack(enc *msgpack.Encoder) error {
@@ -94,6 +99,12 @@ func (b *banner) EncodeMsgpack(enc *msgpack.Encoder) error {
 	e.EncodeString("eventID")
 	e.EncodeInt(b.eventID)
 
+	e.EncodeString("voice")
+	e.EncodeInterface(b.voice)
+
+	e.EncodeString("speaker")
+	e.EncodeInterface(b.speaker)
+
 	e.EndMap()
 	return e.Flush()
 }
@@ -135,15 +146,29 @@ func (b *banner) DecodeMsgpack(dec *msgpack.Decoder) error {
 			}
 		case "eventID":
 			b.eventID = d.DecodeInt()
+		case "voice":
+			if !d.SkipCodeIfNil() {
+				b.voice = &voice{}
+				d.DecodeInterface(b.voice)
+			}
+		case "speaker":
+			if !d.SkipCodeIfNil() {
+				b.speaker = &speaker{}
+				d.DecodeInterface(b.speaker)
+			}
 		}
 	}
 	if err := d.Error(); err != nil {
 		return fmt.Errorf("window: banner.DecodeMsgpack failed: %v", err)
 	}
+	// Don't replay the voice that was already played before saving.
+	if b.voice != nil {
+		b.voice.played = b.opened || b.closingCount > 0
+	}
 	return nil
 }
 
-func newBanner(contentID data.UUID, content string, eventID int, background data.MessageBackground, positionType data.MessagePositionType, textAlign data.TextAlign, interpreterID consts.InterpreterID, messageStyle *data.MessageStyle) *banner {
+func newBanner(contentID data.UUID, content string, eventID int, background data.MessageBackground, positionType data.MessagePositionType, textAlign data.TextAlign, interpreterID consts.InterpreterID, messageStyle *data.MessageStyle, voice *voice, speaker *speaker) *banner {
 	font.DrawTextToScratchPad(content, consts.TextScale, lang.Get())
 
 	b := &banner{
@@ -155,12 +180,50 @@ func newBanner(contentID data.UUID, content string, eventID int, background data
 		textAlign:     textAlign,
 		messageStyle:  messageStyle,
 		eventID:       eventID,
+		voice:         voice,
+		speaker:       speaker,
 	}
+	b.content = b.wrapContent(content)
 	return b
 }
 
+// localizedTextAlign returns the text align in the current language.
+// Left and right mean the start and the end of lines, that are swapped in a right-to-left language.
+func localizedTextAlign(textAlign data.TextAlign) data.TextAlign {
+	if !lang.IsRTL(lang.Get()) {
+		return textAlign
+	}
+	switch textAlign {
+	case data.TextAlignLeft:
+		return data.TextAlignRight
+	case data.TextAlignRight:
+		return data.TextAlignLeft
+	}
+	return textAlign
+}
+
+func (b *banner) textScale() int {
+	if b.background == data.MessageBackgroundTransparent {
+		return consts.BigTextScale
+	}
+	return consts.TextScale
+}
+
+// textAreaWidth returns the width of the text area in the tile unit. The text area excludes the face.
+func (b *banner) textAreaWidth() int {
+	w := consts.MapWidth - 2*bannerPaddingX
+	if fw, _, _ := b.speaker.faceSize(bannerHeight); fw > 0 {
+		w -= fw + bannerPaddingX
+	}
+	return w
+}
+
+func (b *banner) wrapContent(content string) string {
+	return wrapContent(content, b.textAreaWidth()*consts.TileScale/b.textScale(), b.messageStyle.Font)
+}
+
 func (b *banner) overwriteContent(content string) {
-	b.content = content
+	b.content = b.wrapContent(content)
 	if b.typingEffect != nil {
 		b.typingEffect.SetContent(b.content, true)
 	}
@@ -175,11 +238,18 @@ func (b *banner) isOpened() bool {
 }
 
 func (b *banner) isAnimating() bool {
-	return b.openingCount > 0 || b.closingCount > 0 || b.typingEffect.isAnimating()
+	return b.openingCount > 0 || b.closingCount > 0 || b.typingEffect.isAnimating() || b.voice.isWaiting()
 }
 
 func (b *banner) trySkipTypingAnim() {
 	b.typingEffect.trySkipAnim()
+	b.voice.skip()
+}
+
+// skipReadText skips the typing effect and the voice regardless of the voice's wait flag.
+func (b *banner) skipReadText() {
+	b.typingEffect.trySkipAnim()
+	b.voice.stop()
 }
 
 func (b *banner) open() {
@@ -189,9 +259,11 @@ func (b *banner) open() {
 
 func (b *banner) close() {
 	b.closingCount = bannerMaxCount
+	b.voice.stop()
 }
 
 func (b *banner) closeImmediately() {
+	b.voice.stop()
 	b.opened = false
 	b.openingCount = 0
 	b.closingCount = 0
@@ -217,6 +289,7 @@ func (b *banner) update(playerY int, character *character.Character) error {
 		if b.openingCount == 0 {
 			b.opened = true
 			b.playCharacterAnim(character)
+			b.voice.play()
 		}
 	}
 	if b.opened && b.typingEffect.isAnimating() {
@@ -285,7 +358,7 @@ func (b *banner) position(screen *ebiten.Image) (int, int) {
 }
 
 func (b *banner) draw(screen *ebiten.Image, offsetX, offsetY int) {
-	textScale := consts.TextScale
+	textScale := b.textScale()
 	textEdge := false
 	rate := 0.0
 	switch {
@@ -305,7 +378,6 @@ func (b *banner) draw(screen *ebiten.Image, offsetX, offsetY int) {
 		// TODO
 	case data.MessageBackgroundTransparent:
 		textEdge = true
-		textScale = consts.BigTextScale
 	case data.MessageBackgroundBanner:
 		if rate > 0 {
 			img := assets.GetImage("system/game/banner.png")
@@ -319,27 +391,53 @@ func (b *banner) draw(screen *ebiten.Image, offsetX, offsetY int) {
 		}
 	}
 
+	// The text area excludes the face.
+	bx, by := b.position(screen)
+	areaX := bx + bannerPaddingX
+	areaW := b.textAreaWidth()
+	if fw, fh, scale := b.speaker.faceSize(bannerHeight); fw > 0 {
+		fx := areaX
+		if b.speaker.isFaceRight() {
+			fx = areaX + areaW + bannerPaddingX
+		} else {
+			areaX += fw + bannerPaddingX
+		}
+		if rate > 0 {
+			g := &ebiten.GeoM{}
+			g.Translate(dx, dy)
+			b.speaker.drawFace(screen, fx, by+bannerHeight-fh, scale, g, rate)
+		}
+	}
+
 	if b.opened {
-		_, th := font.MeasureSize(b.content)
-		x, y := b.position(screen)
-		x = (x + bannerPaddingX) * consts.TileScale
-		y = (y + (bannerHeight-th*textScale/consts.TileScale)/2) * consts.TileScale
-		switch b.textAlign {
+		_, th := measureContent(visibleContent(b.content), b.messageStyle.Font)
+		x := areaX * consts.TileScale
+		y := (by + (bannerHeight-th*textScale/consts.TileScale)/2) * consts.TileScale
+		textAlign := localizedTextAlign(b.textAlign)
+		switch textAlign {
 		case data.TextAlignLeft:
 		case data.TextAlignCenter:
-			x += (consts.MapWidth - 2*bannerPaddingX) * consts.TileScale / 2
+			x += areaW * consts.TileScale / 2
 		case data.TextAlignRight:
-			x += (consts.MapWidth - 2*bannerPaddingX) * consts.TileScale
+			x += areaW * consts.TileScale
 		}
 		x += int(dx * consts.TileScale)
 		y += int(dy * consts.TileScale)
 
+		if b.speaker.hasName() {
+			nx := (areaX + int(dx)) * consts.TileScale
+			if b.speaker.isFaceRight() {
+				nx = (areaX + areaW + int(dx)) * consts.TileScale
+			}
+			b.speaker.drawName(screen, nx, y-speakerNamePadding*consts.TileScale, b.speaker.isFaceRight())
+		}
+
 		var edgeColor color.Color
 		var shadowColor color.Color
 		if textEdge {
 			edgeColor = color.Black
 			shadowColor = color.RGBA{0, 0, 0, 64}
 		}
-		b.typingEffect.draw(screen, x, y, textScale, b.textAlign, color.White, edgeColor, shadowColor)
+		b.typingEffect.draw(screen, x, y, textScale, textAlign, color.White, edgeColor, shadowColor, b.messageStyle.Font)
 	}
 }
@@ -0,0 +1,142 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package window
+
+import (
+	"fmt"
+
+	"github.com/vmihailenco/msgpack"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/easymsgpack"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/lang"
+)
+
+const maxHistoryEntries = 100
+
+type HistoryEntryType string
+
+const (
+	HistoryEntryTypeMessage HistoryEntryType = "message"
+	HistoryEntryTypeBalloon HistoryEntryType = "balloon"
+	HistoryEntryTypeChoice  HistoryEntryType = "choice"
+)
+
+// HistoryEntry is a record of a shown message, balloon or chosen choice.
+type HistoryEntry struct {
+	Type          HistoryEntryType
+	EventID       int
+	ContentID     data.UUID
+	Text          string
+	SpeakerNameID data.UUID
+	Speaker       string
+}
+
+func (h *HistoryEntry) EncodeMsgpack(enc *msgpack.Encoder) error {
+	e := easymsgpack.NewEncoder(enc)
+	e.BeginMap()
+
+	e.EncodeString("type")
+	e.EncodeString(string(h.Type))
+
+	e.EncodeString("eventId")
+	e.EncodeInt(h.EventID)
+
+	e.EncodeString("contentId")
+	e.EncodeInterface(&h.ContentID)
+
+	e.EncodeString("text")
+	e.EncodeString(h.Text)
+
+	e.EncodeString("speakerNameId")
+	e.EncodeInterface(&h.SpeakerNameID)
+
+	e.EncodeString("speaker")
+	e.EncodeString(h.Speaker)
+
+	e.EndMap()
+	return e.Flush()
+}
+
+func (h *HistoryEntry) DecodeMsgpack(dec *msgpack.Decoder) error {
+	d := easymsgpack.NewDecoder(dec)
+	n := d.DecodeMapLen()
+	for i := 0; i < n; i++ {
+		switch d.DecodeString() {
+		case "type":
+			h.Type = HistoryEntryType(d.DecodeString())
+		case "eventId":
+			h.EventID = d.DecodeInt()
+		case "contentId":
+			d.DecodeInterface(&h.ContentID)
+		case "text":
+			h.Text = d.DecodeString()
+		case "speakerNameId":
+			d.DecodeInterface(&h.SpeakerNameID)
+		case "speaker":
+			h.Speaker = d.DecodeString()
+		}
+	}
+	if err := d.Error(); err != nil {
+		return fmt.Errorf("window: HistoryEntry.DecodeMsgpack failed: %v", err)
+	}
+	return nil
+}
+
+// History returns the shown messages in the order of time.
+func (w *Windows) History() []*HistoryEntry {
+	return w.history
+}
+
+func (w *Windows) addHistory(entryType HistoryEntryType, eventID int, contentID data.UUID, content string, speaker *speaker) {
+	h := &HistoryEntry{
+		Type:      entryType,
+		EventID:   eventID,
+		ContentID: contentID,
+		Text:      stripMarkup(visibleContent(content), false),
+	}
+	if speaker != nil {
+		h.SpeakerNameID = speaker.nameID
+		h.Speaker = speaker.name
+	}
+	w.history = append(w.history, h)
+	if len(w.history) > maxHistoryEntries {
+		w.history = w.history[len(w.history)-maxHistoryEntries:]
+	}
+}
+
+func (w *Windows) AddHistoryForTesting(content string) {
+	w.addHistory(HistoryEntryTypeMessage, 0, data.UUID{}, content, nil)
+}
+
+// updateHistoryLanguage resolves the texts in the history again in the current language.
+// Texts with message commands like \v[1] are kept as they were shown, since the values might be changed after that.
+func (w *Windows) updateHistoryLanguage(parser MessageSyntaxParser, game *data.Game) {
+	for _, h := range w.history {
+		if h.SpeakerNameID != (data.UUID{}) {
+			if name := game.Texts.Get(lang.Get(), h.SpeakerNameID); parser.ParseMessageSyntax(name) == name {
+				h.Speaker = name
+			}
+		}
+		if h.ContentID == (data.UUID{}) {
+			continue
+		}
+		content := game.Texts.Get(lang.Get(), h.ContentID)
+		if parser.ParseMessageSyntax(content) != content {
+			continue
+		}
+		h.Text = stripMarkup(visibleContent(content), false)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package window_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/vmihailenco/msgpack"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+	. "github.com/hajimehoshi/rpgsnack-runtime/internal/window"
+)
+
+func TestHistory(t *testing.T) {
+	w := &Windows{}
+	for i := 0; i < 105; i++ {
+		w.AddHistoryForTesting(fmt.Sprintf(`\c[#ff0000]message\c[/] %d\.`, i))
+	}
+	h := w.History()
+	if got, want := len(h), 100; got != want {
+		t.Fatalf("len(History()): got: %d, want: %d", got, want)
+	}
+	if got, want := h[0].Text, "message 5"; got != want {
+		t.Errorf("History()[0].Text: got: %q, want: %q", got, want)
+	}
+	if got, want := h[99].Text, "message 104"; got != want {
+		t.Errorf("History()[99].Text: got: %q, want: %q", got, want)
+	}
+}
+
+func TestMarshalHistoryEntry(t *testing.T) {
+	h := &HistoryEntry{
+		Type:          HistoryEntryTypeMessage,
+		EventID:       3,
+		ContentID:     data.UUID{1, 2, 3},
+		Text:          "Hello",
+		SpeakerNameID: data.UUID{4, 5, 6},
+		Speaker:       "Alice",
+	}
+	b, err := msgpack.Marshal(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var h2 *HistoryEntry
+	if err := msgpack.Unmarshal(b, &h2); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(h2, h) {
+		t.Errorf("got: %#v, want: %#v", h2, h)
+	}
+}
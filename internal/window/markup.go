@@ -0,0 +1,307 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package window
+
+import (
+	"encoding/hex"
+	"image/color"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/assets"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/font"
+)
+
+// Markup tags in message texts:
+//
+//	\c[#rrggbb] ... \c[/]            changes the text color.
+//	\s[1.5] ... \s[/]                changes the text scale.
+//	\e[shake|wave|rainbow] ... \e[/] adds an effect. Effects can be combined.
+//	\g[icon]                         shows an inline icon. An item ID is converted to its icon at the parser.
+//	\r[base|ruby]                    shows a ruby text like furigana above the base text.
+const (
+	markupColor  = 'c'
+	markupScale  = 's'
+	markupEffect = 'e'
+	markupIcon   = 'g'
+	markupRuby   = 'r'
+
+	markupEnd     = "/"
+	rubySeparator = "|"
+
+	// unitRune is the rune to represent a unit like an inline icon in plain texts.
+	unitRune = '￼'
+
+	minMarkupScale = 0.5
+	maxMarkupScale = 3
+)
+
+// markupLength returns the length of the markup tag starting at i and its argument.
+// markupLength returns 0 if there is no tag at i.
+func markupLength(rs []rune, i int) (int, rune, string) {
+	if i+3 >= len(rs) || rs[i] != '\\' || rs[i+2] != '[' {
+		return 0, 0, ""
+	}
+	switch rs[i+1] {
+	case markupColor, markupScale, markupEffect, markupIcon, markupRuby:
+	default:
+		return 0, 0, ""
+	}
+	for j := i + 3; j < len(rs); j++ {
+		switch rs[j] {
+		case ']':
+			arg := string(rs[i+3 : j])
+			if rs[i+1] == markupRuby {
+				if _, _, ok := parseRuby(arg); !ok {
+					return 0, 0, ""
+				}
+			}
+			return j - i + 1, rs[i+1], arg
+		case '\\', '\n':
+			return 0, 0, ""
+		}
+	}
+	return 0, 0, ""
+}
+
+func iconExists(name string) bool {
+	return assets.Exists("images/icons/" + name + ".png")
+}
+
+func parseRuby(arg string) (string, string, bool) {
+	ts := strings.SplitN(arg, rubySeparator, 2)
+	if len(ts) != 2 || ts[0] == "" || ts[1] == "" {
+		return "", "", false
+	}
+	return ts[0], ts[1], true
+}
+
+// isUnitMarkup reports whether the tag is shown as one unit, that is counted as one rune at the typing effect.
+func isUnitMarkup(tag rune, arg string) bool {
+	switch tag {
+	case markupIcon:
+		return iconExists(arg)
+	case markupRuby:
+		return true
+	}
+	return false
+}
+
+// stripMarkup removes the markup tags.
+// If units is true, units like icons are replaced with unitRune. Otherwise, only the base texts of ruby are kept.
+func stripMarkup(content string, units bool) string {
+	rs := []rune(content)
+	var b strings.Builder
+	for i := 0; i < len(rs); i++ {
+		n, tag, arg := markupLength(rs, i)
+		if n == 0 {
+			b.WriteRune(rs[i])
+			continue
+		}
+		switch {
+		case units && isUnitMarkup(tag, arg):
+			b.WriteRune(unitRune)
+		case tag == markupRuby:
+			base, _, _ := parseRuby(arg)
+			b.WriteString(base)
+		}
+		i += n - 1
+	}
+	return b.String()
+}
+
+// plainContent returns the content without control characters and markup tags.
+// Each rune of the result corresponds to a step of the typing effect.
+func plainContent(content string) string {
+	return stripMarkup(visibleContent(content), true)
+}
+
+func PlainContentForTesting(content string) string {
+	return plainContent(content)
+}
+
+func parseMarkupColor(arg string) color.Color {
+	bin, err := hex.DecodeString(strings.TrimPrefix(arg, "#"))
+	if err != nil || len(bin) != 3 {
+		return nil
+	}
+	return color.RGBA{bin[0], bin[1], bin[2], 0xff}
+}
+
+// parseMarkup parses the content with markup tags into spans.
+// The content should not include control characters.
+// fontName is the name of the font for all the spans. An empty string means the default fonts.
+func parseMarkup(content string, fontName string) []font.TextSpan {
+	var spans []font.TextSpan
+	current := font.TextSpan{
+		Font: fontName,
+	}
+	var text []rune
+	flush := func() {
+		if len(text) == 0 {
+			return
+		}
+		s := current
+		s.Text = string(text)
+		spans = append(spans, s)
+		text = nil
+	}
+
+	rs := []rune(content)
+	for i := 0; i < len(rs); i++ {
+		n, tag, arg := markupLength(rs, i)
+		if n == 0 {
+			text = append(text, rs[i])
+			continue
+		}
+		i += n - 1
+		flush()
+
+		switch tag {
+		case markupColor:
+			if arg == markupEnd {
+				current.Color = nil
+				break
+			}
+			if c := parseMarkupColor(arg); c != nil {
+				current.Color = c
+			}
+		case markupScale:
+			if arg == markupEnd {
+				current.Scale = 0
+				break
+			}
+			s, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				break
+			}
+			if s < minMarkupScale {
+				s = minMarkupScale
+			}
+			if s > maxMarkupScale {
+				s = maxMarkupScale
+			}
+			current.Scale = s
+		case markupEffect:
+			switch arg {
+			case markupEnd:
+				current.Effect = 0
+			case "shake":
+				current.Effect |= font.TextEffectShake
+			case "wave":
+				current.Effect |= font.TextEffectWave
+			case "rainbow":
+				current.Effect |= font.TextEffectRainbow
+			}
+		case markupIcon:
+			if !iconExists(arg) {
+				break
+			}
+			s := current
+			s.Color = nil
+			s.Icon = assets.GetIconImage(arg + ".png")
+			spans = append(spans, s)
+		case markupRuby:
+			s := current
+			s.Text, s.Ruby, _ = parseRuby(arg)
+			spans = append(spans, s)
+		}
+	}
+	flush()
+	return spans
+}
+
+func ParseMarkupForTesting(content string) []font.TextSpan {
+	return parseMarkup(content, "")
+}
+
+// measureContent returns the size of the content with markup tags in the same unit as font.MeasureSize.
+func measureContent(content string, fontName string) (int, int) {
+	return font.MeasureSpans(parseMarkup(content, fontName))
+}
+
+// wrapContent inserts line breaks into the content so that each line fits in width.
+// width is in the same unit as font.MeasureSize.
+// The spaces at a line break are replaced with the line break.
+func wrapContent(content string, width int, fontName string) string {
+	content = font.ToValidContent(content)
+	breaks := font.LineBreaks(parseMarkup(visibleContent(content), fontName), width)
+	if len(breaks) == 0 {
+		return content
+	}
+
+	rs := []rune(content)
+	var r []rune
+	// index is the position in the same unit as the typing effect's visible index.
+	index := 0
+	for i := 0; i < len(rs); {
+		if hasRunePrefix(rs[i:], controlForceQuit) {
+			r = append(r, rs[i:]...)
+			break
+		}
+		if hasRunePrefix(rs[i:], controlWaitShort) {
+			r = append(r, []rune(controlWaitShort)...)
+			i += len(controlWaitShort)
+			continue
+		}
+		if hasRunePrefix(rs[i:], controlWaitLong) {
+			r = append(r, []rune(controlWaitLong)...)
+			i += len(controlWaitLong)
+			continue
+		}
+		n, tag, arg := markupLength(rs, i)
+		if n > 0 && !isUnitMarkup(tag, arg) {
+			r = append(r, rs[i:i+n]...)
+			i += n
+			continue
+		}
+
+		if len(breaks) > 0 && breaks[0] == index {
+			for len(r) > 0 && r[len(r)-1] != '\n' && unicode.IsSpace(r[len(r)-1]) {
+				r = r[:len(r)-1]
+			}
+			r = append(r, '\n')
+			breaks = breaks[1:]
+		}
+
+		if n == 0 {
+			n = 1
+		}
+		r = append(r, rs[i:i+n]...)
+		i += n
+		index++
+	}
+	return string(r)
+}
+
+func hasRunePrefix(rs []rune, prefix string) bool {
+	p := []rune(prefix)
+	if len(rs) < len(p) {
+		return false
+	}
+	return string(rs[:len(p)]) == prefix
+}
+
+// spansForOutline returns the spans for shadows and edges, that are drawn in a single color.
+func spansForOutline(spans []font.TextSpan, clr color.Color) []font.TextSpan {
+	r := make([]font.TextSpan, len(spans))
+	for i, s := range spans {
+		s.Color = clr
+		s.Effect &^= font.TextEffectRainbow
+		r[i] = s
+	}
+	return r
+}
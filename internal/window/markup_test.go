@@ -0,0 +1,125 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package window_test
+
+import (
+	"image/color"
+	"reflect"
+	"testing"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/font"
+	. "github.com/hajimehoshi/rpgsnack-runtime/internal/window"
+)
+
+func TestParseMarkup(t *testing.T) {
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	cases := []struct {
+		In  string
+		Out []font.TextSpan
+	}{
+		{
+			In:  "Hello",
+			Out: []font.TextSpan{{Text: "Hello"}},
+		},
+		{
+			In: `A\c[#ff0000]B\c[/]C`,
+			Out: []font.TextSpan{
+				{Text: "A"},
+				{Text: "B", Color: red},
+				{Text: "C"},
+			},
+		},
+		{
+			// The scale is clamped.
+			In: `\s[5]A\s[0.1]B\s[/]C`,
+			Out: []font.TextSpan{
+				{Text: "A", Scale: 3},
+				{Text: "B", Scale: 0.5},
+				{Text: "C"},
+			},
+		},
+		{
+			// Effects are combined.
+			In: `\e[shake]A\e[wave]B\e[/]C`,
+			Out: []font.TextSpan{
+				{Text: "A", Effect: font.TextEffectShake},
+				{Text: "B", Effect: font.TextEffectShake | font.TextEffectWave},
+				{Text: "C"},
+			},
+		},
+		{
+			In: `\c[#ff0000]\s[2]A\c[/]B`,
+			Out: []font.TextSpan{
+				{Text: "A", Color: red, Scale: 2},
+				{Text: "B", Scale: 2},
+			},
+		},
+		{
+			In: `\c[#ff0000]\r[漢字|かんじ]です`,
+			Out: []font.TextSpan{
+				{Text: "漢字", Ruby: "かんじ", Color: red},
+				{Text: "です", Color: red},
+			},
+		},
+		{
+			// An invalid argument is ignored.
+			In:  `\c[#zzzzzz]A`,
+			Out: []font.TextSpan{{Text: "A"}},
+		},
+		{
+			// Unknown or unclosed tags are shown as they are.
+			In:  `\x[1]A\c[#ff0000`,
+			Out: []font.TextSpan{{Text: `\x[1]A\c[#ff0000`}},
+		},
+	}
+	for _, c := range cases {
+		got := ParseMarkupForTesting(c.In)
+		if !reflect.DeepEqual(got, c.Out) {
+			t.Errorf("ParseMarkupForTesting(%q): got: %v, want: %v", c.In, got, c.Out)
+		}
+	}
+}
+
+func TestPlainContent(t *testing.T) {
+	cases := []struct {
+		In  string
+		Out string
+	}{
+		{
+			In:  `A\c[#ff0000]B\c[/]\.C`,
+			Out: "ABC",
+		},
+		{
+			// A ruby is counted as one unit.
+			In:  `\r[漢字|かんじ]です`,
+			Out: "\ufffcです",
+		},
+		{
+			In:  `\r[漢字|かんじ]\r[東京|とうきょう]`,
+			Out: "\ufffc\ufffc",
+		},
+		{
+			// A ruby without a base or an annotation is shown as it is.
+			In:  `\r[漢字]です\r[|かんじ]`,
+			Out: `\r[漢字]です\r[|かんじ]`,
+		},
+	}
+	for _, c := range cases {
+		got := PlainContentForTesting(c.In)
+		if got != c.Out {
+			t.Errorf("PlainContentForTesting(%q): got: %q, want: %q", c.In, got, c.Out)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package window
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/vmihailenco/msgpack"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/assets"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/consts"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/easymsgpack"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/font"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/lang"
+)
+
+const speakerNamePadding = 2
+
+var speakerNameBoxImage *ebiten.Image
+
+func init() {
+	speakerNameBoxImage, _ = ebiten.NewImage(1, 1, ebiten.FilterDefault)
+	speakerNameBoxImage.Fill(color.RGBA{0, 0, 0, 0xc0})
+}
+
+// speaker is a speaker's name and face shown with a message window.
+type speaker struct {
+	nameID       data.UUID
+	name         string
+	face         string
+	faceFrame    int
+	facePosition data.FacePosition
+}
+
+func newSpeaker(s *data.MessageSpeaker, parser MessageSyntaxParser, game *data.Game) *speaker {
+	if s == nil {
+		return nil
+	}
+	if s.NameID == (data.UUID{}) && s.Name == "" && s.Face == "" {
+		return nil
+	}
+	sp := &speaker{
+		nameID:       s.NameID,
+		name:         s.Name,
+		face:         s.Face,
+		faceFrame:    s.FaceFrame,
+		facePosition: s.FacePosition,
+	}
+	sp.updateName(parser, game)
+	return sp
+}
+
+func (s *speaker) EncodeMsgpack(enc *msgpack.Encoder) error {
+	e := easymsgpack.NewEncoder(enc)
+	e.BeginMap()
+
+	e.EncodeString("nameId")
+	e.EncodeInterface(&s.nameID)
+
+	e.EncodeString("name")
+	e.EncodeString(s.name)
+
+	e.EncodeString("face")
+	e.EncodeString(s.face)
+
+	e.EncodeString("faceFrame")
+	e.EncodeInt(s.faceFrame)
+
+	e.EncodeString("facePosition")
+	e.EncodeString(string(s.facePosition))
+
+	e.EndMap()
+	return e.Flush()
+}
+
+func (s *speaker) DecodeMsgpack(dec *msgpack.Decoder) error {
+	d := easymsgpack.NewDecoder(dec)
+	n := d.DecodeMapLen()
+	for i := 0; i < n; i++ {
+		switch d.DecodeString() {
+		case "nameId":
+			d.DecodeInterface(&s.nameID)
+		case "name":
+			s.name = d.DecodeString()
+		case "face":
+			s.face = d.DecodeString()
+		case "faceFrame":
+			s.faceFrame = d.DecodeInt()
+		case "facePosition":
+			s.facePosition = data.FacePosition(d.DecodeString())
+		}
+	}
+	if err := d.Error(); err != nil {
+		return fmt.Errorf("window: speaker.DecodeMsgpack failed: %v", err)
+	}
+	return nil
+}
+
+// updateName resolves the name in the current language.
+func (s *speaker) updateName(parser MessageSyntaxParser, game *data.Game) {
+	if s == nil || s.nameID == (data.UUID{}) {
+		return
+	}
+	s.name = parser.ParseMessageSyntax(game.Texts.Get(lang.Get(), s.nameID))
+}
+
+func (s *speaker) hasName() bool {
+	return s != nil && s.name != ""
+}
+
+func (s *speaker) hasFace() bool {
+	return s != nil && s.face != ""
+}
+
+func (s *speaker) isFaceRight() bool {
+	return s != nil && s.facePosition == data.FacePositionRight
+}
+
+func (s *speaker) faceImage() *ebiten.Image {
+	img := assets.GetLocalizedImage("pictures/" + s.face)
+	w, h := img.Size()
+	if w <= h {
+		return img
+	}
+	n := w / h
+	f := s.faceFrame
+	if f < 0 || n <= f {
+		f = 0
+	}
+	return img.SubImage(image.Rect(f*h, 0, (f+1)*h, h)).(*ebiten.Image)
+}
+
+// faceSize returns the face size in the tile unit that fits with maxHeight.
+// If maxHeight is 0, the face is not scaled.
+func (s *speaker) faceSize(maxHeight int) (int, int, float64) {
+	if !s.hasFace() {
+		return 0, 0, 0
+	}
+	w, h := s.faceImage().Size()
+	if maxHeight <= 0 || h <= maxHeight {
+		return w, h, 1
+	}
+	scale := float64(maxHeight) / float64(h)
+	return int(float64(w) * scale), maxHeight, scale
+}
+
+// drawFace draws the face at (x, y) in the tile unit.
+func (s *speaker) drawFace(screen *ebiten.Image, x, y int, scale float64, geoM *ebiten.GeoM, alpha float64) {
+	if !s.hasFace() {
+		return
+	}
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(scale, scale)
+	op.GeoM.Translate(float64(x), float64(y))
+	if geoM != nil {
+		op.GeoM.Concat(*geoM)
+	}
+	op.GeoM.Scale(consts.TileScale, consts.TileScale)
+	op.ColorM.Scale(1, 1, 1, alpha)
+	screen.DrawImage(s.faceImage(), op)
+}
+
+// drawName draws the name box whose bottom-left or bottom-right is at (x, y) in pixels.
+func (s *speaker) drawName(screen *ebiten.Image, x, y int, alignRight bool) {
+	if !s.hasName() {
+		return
+	}
+	tw, th := font.MeasureSize(s.name)
+	tw *= consts.TextScale
+	th *= consts.TextScale
+	p := speakerNamePadding * consts.TileScale
+	w := tw + 2*p
+	h := th + 2*p
+	if alignRight {
+		x -= w
+	}
+	y -= h
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(float64(w), float64(h))
+	op.GeoM.Translate(float64(x), float64(y))
+	screen.DrawImage(speakerNameBoxImage, op)
+
+	font.DrawText(screen, s.name, x+p, y+p, &font.DrawTextOptions{
+		Scale:    consts.TextScale,
+		Color:    color.White,
+		Language: lang.Get(),
+	})
+}
@@ -37,6 +37,7 @@ type typingEffect struct {
 	index                     int
 	delayCount                int
 	isSEPlayedInPreviousFrame bool
+	time                      int
 }
 
 func newTypingEffect(content string, delay int, soundEffect string) *typingEffect {
@@ -106,12 +107,11 @@ func (t *typingEffect) visibleContent() []rune {
 	return []rune(visibleContent(string(t.content)))
 }
 
+// visibleIndex returns the number of the shown runes in the plain content.
 func (t *typingEffect) visibleIndex() int {
-	i := t.index
-	i -= strings.Count(string(t.content[:t.index]), controlWaitShort) * len(controlWaitShort)
-	i -= strings.Count(string(t.content[:t.index]), controlWaitLong) * len(controlWaitLong)
+	i := len([]rune(plainContent(string(t.content[:t.index]))))
 
-	max := len(t.visibleContent())
+	max := len([]rune(plainContent(string(t.content))))
 	if i > max {
 		i = max
 	}
@@ -158,10 +158,25 @@ func (t *typingEffect) SetContent(content string, overwrite bool) {
 }
 
 func (t *typingEffect) update() {
+	t.time++
 	if t.delayCount > 0 {
 		t.delayCount--
 	}
 	if t.delayCount == 0 {
+		// Style tags are not shown and are skipped immediately.
+		step := 1
+		for t.index < t.lastIndex() {
+			n, tag, arg := markupLength(t.content[:t.lastIndex()], t.index)
+			if n == 0 {
+				break
+			}
+			if isUnitMarkup(tag, arg) {
+				step = n
+				break
+			}
+			t.index += n
+		}
+
 		switch {
 		case t.hasControl([]rune(controlWaitShort)):
 			t.delayCount = 15
@@ -175,7 +190,7 @@ func (t *typingEffect) update() {
 
 		played := false
 		if t.index < t.lastIndex() {
-			t.index++
+			t.index += step
 			if !t.isSEPlayedInPreviousFrame && !t.isLastRuneSpace() {
 				played = t.playSE()
 			}
@@ -212,9 +227,9 @@ func (t *typingEffect) playSE() bool {
 	return true
 }
 
-func (t *typingEffect) draw(screen *ebiten.Image, x, y int, textScale int, textAlign data.TextAlign, textColor color.Color, edgeColor color.Color, shadowColor color.Color) {
+func (t *typingEffect) draw(screen *ebiten.Image, x, y int, textScale int, textAlign data.TextAlign, textColor color.Color, edgeColor color.Color, shadowColor color.Color, fontName string) {
 	i := t.visibleIndex()
-	str := string(t.visibleContent())
+	spans := parseMarkup(string(t.visibleContent()), fontName)
 	s := float64(textScale)
 
 	op := &font.DrawTextOptions{
@@ -222,28 +237,31 @@ func (t *typingEffect) draw(screen *ebiten.Image, x, y int, textScale int, textA
 		TextAlign:    textAlign,
 		UseRuneCount: true,
 		RuneCount:    i,
+		Time:         t.time,
 	}
 
 	if shadowColor != nil {
 		// Shadow
+		ss := spansForOutline(spans, shadowColor)
 		op.Color = shadowColor
-		font.DrawText(screen, str, x+textScale*2, y, op)
-		font.DrawText(screen, str, x-textScale*2, y, op)
-		font.DrawText(screen, str, x, y+textScale*2, op)
-		font.DrawText(screen, str, x, y-textScale*2, op)
-		font.DrawText(screen, str, x+textScale, y+textScale, op)
-		font.DrawText(screen, str, x-textScale, y+textScale, op)
-		font.DrawText(screen, str, x+textScale, y-textScale, op)
-		font.DrawText(screen, str, x-textScale, y-textScale, op)
+		font.DrawRichText(screen, ss, x+textScale*2, y, op)
+		font.DrawRichText(screen, ss, x-textScale*2, y, op)
+		font.DrawRichText(screen, ss, x, y+textScale*2, op)
+		font.DrawRichText(screen, ss, x, y-textScale*2, op)
+		font.DrawRichText(screen, ss, x+textScale, y+textScale, op)
+		font.DrawRichText(screen, ss, x-textScale, y+textScale, op)
+		font.DrawRichText(screen, ss, x+textScale, y-textScale, op)
+		font.DrawRichText(screen, ss, x-textScale, y-textScale, op)
 
 		// Edge
+		es := spansForOutline(spans, edgeColor)
 		op.Color = edgeColor
-		font.DrawText(screen, str, x+textScale, y, op)
-		font.DrawText(screen, str, x-textScale, y, op)
-		font.DrawText(screen, str, x, y+textScale, op)
-		font.DrawText(screen, str, x, y-textScale, op)
+		font.DrawRichText(screen, es, x+textScale, y, op)
+		font.DrawRichText(screen, es, x-textScale, y, op)
+		font.DrawRichText(screen, es, x, y+textScale, op)
+		font.DrawRichText(screen, es, x, y-textScale, op)
 	}
 
 	op.Color = textColor
-	font.DrawText(screen, str, x, y, op)
+	font.DrawRichText(screen, spans, x, y, op)
 }
@@ -0,0 +1,109 @@
+// Copyright 2019 The RPGSnack Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package window
+
+import (
+	"fmt"
+
+	"github.com/vmihailenco/msgpack"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/audio"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/easymsgpack"
+)
+
+const voiceVolume = 1
+
+// voice is a voice line played with a message window.
+type voice struct {
+	name string
+	wait bool
+
+	// played is not dumped.
+	played bool
+}
+
+func newVoice(name string, wait bool) *voice {
+	if name == "" {
+		return nil
+	}
+	return &voice{
+		name: name,
+		wait: wait,
+	}
+}
+
+func (v *voice) EncodeMsgpack(enc *msgpack.Encoder) error {
+	e := easymsgpack.NewEncoder(enc)
+	e.BeginMap()
+
+	e.EncodeString("name")
+	e.EncodeString(v.name)
+
+	e.EncodeString("wait")
+	e.EncodeBool(v.wait)
+
+	e.EndMap()
+	return e.Flush()
+}
+
+func (v *voice) DecodeMsgpack(dec *msgpack.Decoder) error {
+	d := easymsgpack.NewDecoder(dec)
+	n := d.DecodeMapLen()
+	for i := 0; i < n; i++ {
+		switch d.DecodeString() {
+		case "name":
+			v.name = d.DecodeString()
+		case "wait":
+			v.wait = d.DecodeBool()
+		}
+	}
+	if err := d.Error(); err != nil {
+		return fmt.Errorf("window: voice.DecodeMsgpack failed: %v", err)
+	}
+	return nil
+}
+
+// play plays the voice if the voice is not played yet.
+func (v *voice) play() {
+	if v == nil || v.played {
+		return
+	}
+	audio.PlayVoice(v.name, voiceVolume)
+	v.played = true
+}
+
+func (v *voice) stop() {
+	if v == nil {
+		return
+	}
+	audio.StopVoice(v.name)
+}
+
+// skip is called when the typing effect is skipped.
+// The voice continues if the window waits for the voice.
+func (v *voice) skip() {
+	if v == nil || v.wait {
+		return
+	}
+	v.stop()
+}
+
+// isWaiting reports whether the window should wait for the voice.
+func (v *voice) isWaiting() bool {
+	if v == nil || !v.wait {
+		return false
+	}
+	return audio.IsVoicePlaying(v.name)
+}
@@ -16,6 +16,7 @@ package window
 
 import (
 	"fmt"
+	"image/color"
 
 	"github.com/hajimehoshi/ebiten"
 	"github.com/vmihailenco/msgpack"
@@ -33,8 +34,23 @@ import (
 const (
 	choiceBalloonHeight        = 20
 	chosenBalloonWaitingFrames = 5
+	choiceTimerHeight          = 2
+
+	// maxChoicesPerPage is the maximum number of the choices shown at once.
+	// When there are more choices, the choices are paged.
+	maxChoicesPerPage = 4
 )
 
+// ChoiceIndexCanceled is the chosen index when the choices are canceled.
+const ChoiceIndexCanceled = -1
+
+var choiceTimerImage *ebiten.Image
+
+func init() {
+	choiceTimerImage, _ = ebiten.NewImage(1, 1, ebiten.FilterDefault)
+	choiceTimerImage.Fill(color.White)
+}
+
 type MessageSyntaxParser interface {
 	ParseMessageSyntax(content string) string
 }
@@ -51,19 +67,90 @@ type Windows struct {
 	choosingInterpreterID     consts.InterpreterID
 	chosenBalloonWaitingCount int
 	hasChosenIndex            bool
+	history                   []*HistoryEntry
+
+	choiceDefaultIndex  int
+	choiceTimeoutCount  int
+	choiceTimeoutFrames int
+	choiceTimeoutIndex  int
+	choiceCancelable    bool
+	choices             []*Choice
+	choicePage          int
+	choicePager         *balloon
 
 	// Not dump
-	lastLang language.Tag
+	lastLang         language.Tag
+	autoMode         bool
+	skipMode         bool
+	autoWaitingCount int
 }
 
 type Choice struct {
-	ID      data.UUID
-	Checked bool
+	ID               data.UUID
+	Checked          bool
+	Icon             string
+	Disabled         bool
+	DisabledReasonID data.UUID
+}
+
+func (c *Choice) EncodeMsgpack(enc *msgpack.Encoder) error {
+	e := easymsgpack.NewEncoder(enc)
+	e.BeginMap()
+
+	e.EncodeString("id")
+	e.EncodeInterface(&c.ID)
+
+	e.EncodeString("checked")
+	e.EncodeBool(c.Checked)
+
+	e.EncodeString("icon")
+	e.EncodeString(c.Icon)
+
+	e.EncodeString("disabled")
+	e.EncodeBool(c.Disabled)
+
+	e.EncodeString("disabledReasonId")
+	e.EncodeInterface(&c.DisabledReasonID)
+
+	e.EndMap()
+	return e.Flush()
+}
+
+func (c *Choice) DecodeMsgpack(dec *msgpack.Decoder) error {
+	d := easymsgpack.NewDecoder(dec)
+	n := d.DecodeMapLen()
+	for i := 0; i < n; i++ {
+		switch d.DecodeString() {
+		case "id":
+			d.DecodeInterface(&c.ID)
+		case "checked":
+			c.Checked = d.DecodeBool()
+		case "icon":
+			c.Icon = d.DecodeString()
+		case "disabled":
+			c.Disabled = d.DecodeBool()
+		case "disabledReasonId":
+			d.DecodeInterface(&c.DisabledReasonID)
+		}
+	}
+	if err := d.Error(); err != nil {
+		return fmt.Errorf("window: Choice.DecodeMsgpack failed: %v", err)
+	}
+	return nil
 }
 
 func (c *Choice) Text(parser MessageSyntaxParser, game *data.Game) string {
 	content := game.Texts.Get(lang.Get(), c.ID)
-	return parser.ParseMessageSyntax(content)
+	content = parser.ParseMessageSyntax(content)
+	if c.Icon != "" {
+		content = `\g[` + c.Icon + `] ` + content
+	}
+	if c.Disabled && c.DisabledReasonID != (data.UUID{}) {
+		reason := game.Texts.Get(lang.Get(), c.DisabledReasonID)
+		reason = parser.ParseMessageSyntax(reason)
+		content += ` \s[0.75](` + reason + `)\s[/]`
+	}
+	return content
 }
 
 func (w *Windows) EncodeMsgpack(enc *msgpack.Encoder) error {
@@ -108,12 +195,49 @@ func (w *Windows) EncodeMsgpack(enc *msgpack.Encoder) error {
 	e.EncodeString("hasChosenIndex")
 	e.EncodeBool(w.hasChosenIndex)
 
+	e.EncodeString("history")
+	e.BeginArray()
+	for _, h := range w.history {
+		e.EncodeInterface(h)
+	}
+	e.EndArray()
+
+	e.EncodeString("choiceDefaultIndex")
+	e.EncodeInt(w.choiceDefaultIndex)
+
+	e.EncodeString("choiceTimeoutCount")
+	e.EncodeInt(w.choiceTimeoutCount)
+
+	e.EncodeString("choiceTimeoutFrames")
+	e.EncodeInt(w.choiceTimeoutFrames)
+
+	e.EncodeString("choiceTimeoutIndex")
+	e.EncodeInt(w.choiceTimeoutIndex)
+
+	e.EncodeString("choiceCancelable")
+	e.EncodeBool(w.choiceCancelable)
+
+	e.EncodeString("choices")
+	e.BeginArray()
+	for _, c := range w.choices {
+		e.EncodeInterface(c)
+	}
+	e.EndArray()
+
+	e.EncodeString("choicePage")
+	e.EncodeInt(w.choicePage)
+
+	e.EncodeString("choicePager")
+	e.EncodeInterface(w.choicePager)
+
 	e.EndMap()
 	return e.Flush()
 }
 
 func (w *Windows) DecodeMsgpack(dec *msgpack.Decoder) error {
 	d := easymsgpack.NewDecoder(dec)
+	// Old data doesn't have the default index.
+	w.choiceDefaultIndex = -1
 	n := d.DecodeMapLen()
 	for i := 0; i < n; i++ {
 		k := d.DecodeString()
@@ -165,6 +289,41 @@ func (w *Windows) DecodeMsgpack(dec *msgpack.Decoder) error {
 			w.chosenBalloonWaitingCount = d.DecodeInt()
 		case "hasChosenIndex":
 			w.hasChosenIndex = d.DecodeBool()
+		case "history":
+			if !d.SkipCodeIfNil() {
+				n := d.DecodeArrayLen()
+				w.history = make([]*HistoryEntry, n)
+				for i := 0; i < n; i++ {
+					w.history[i] = &HistoryEntry{}
+					d.DecodeInterface(w.history[i])
+				}
+			}
+		case "choiceDefaultIndex":
+			w.choiceDefaultIndex = d.DecodeInt()
+		case "choiceTimeoutCount":
+			w.choiceTimeoutCount = d.DecodeInt()
+		case "choiceTimeoutFrames":
+			w.choiceTimeoutFrames = d.DecodeInt()
+		case "choiceTimeoutIndex":
+			w.choiceTimeoutIndex = d.DecodeInt()
+		case "choiceCancelable":
+			w.choiceCancelable = d.DecodeBool()
+		case "choices":
+			if !d.SkipCodeIfNil() {
+				n := d.DecodeArrayLen()
+				w.choices = make([]*Choice, n)
+				for i := 0; i < n; i++ {
+					w.choices[i] = &Choice{}
+					d.DecodeInterface(w.choices[i])
+				}
+			}
+		case "choicePage":
+			w.choicePage = d.DecodeInt()
+		case "choicePager":
+			if !d.SkipCodeIfNil() {
+				w.choicePager = &balloon{}
+				d.DecodeInterface(w.choicePager)
+			}
 		default:
 			if err := d.Error(); err != nil {
 				return err
@@ -181,6 +340,7 @@ func (w *Windows) DecodeMsgpack(dec *msgpack.Decoder) error {
 // ChosenIndex returns the chosen index that are chosen lastly.
 // This value is valid even after the choosing windows are closed.
 // This value is invalidated when the new choosing windows are shown.
+// ChosenIndex returns ChoiceIndexCanceled when the choices are canceled.
 func (w *Windows) ChosenIndex() int {
 	return w.chosenIndex
 }
@@ -189,27 +349,32 @@ func (w *Windows) HasChosenIndex() bool {
 	return w.hasChosenIndex
 }
 
-func (w *Windows) ShowBalloon(contentID data.UUID, parser MessageSyntaxParser, game *data.Game, balloonType data.BalloonType, eventID int, interpreterID consts.InterpreterID, messageStyle *data.MessageStyle) {
+func (w *Windows) ShowBalloon(contentID data.UUID, parser MessageSyntaxParser, game *data.Game, balloonType data.BalloonType, eventID int, interpreterID consts.InterpreterID, messageStyle *data.MessageStyle, voiceName string, waitVoice bool, speaker *data.MessageSpeaker) {
 	if w.nextBalloon != nil {
 		panic("window: nextBalloon must be nil at ShowBalloon")
 	}
 	// TODO: How to call newBalloonCenter?
 	content := game.Texts.Get(lang.Get(), contentID)
 	content = parser.ParseMessageSyntax(content)
-	w.nextBalloon = newBalloonWithArrow(contentID, content, balloonType, eventID, interpreterID, messageStyle)
+	w.nextBalloon = newBalloonWithArrow(contentID, content, balloonType, eventID, interpreterID, messageStyle, newVoice(voiceName, waitVoice), newSpeaker(speaker, parser, game))
 }
 
-func (w *Windows) ShowMessage(contentID data.UUID, parser MessageSyntaxParser, game *data.Game, eventID int, background data.MessageBackground, positionType data.MessagePositionType, textAlign data.TextAlign, interpreterID consts.InterpreterID, messageStyle *data.MessageStyle) {
+func (w *Windows) ShowMessage(contentID data.UUID, parser MessageSyntaxParser, game *data.Game, eventID int, background data.MessageBackground, positionType data.MessagePositionType, textAlign data.TextAlign, interpreterID consts.InterpreterID, messageStyle *data.MessageStyle, voiceName string, waitVoice bool, speaker *data.MessageSpeaker) {
 	if w.nextBanner != nil {
 		panic("window: nextBalloon must be nil at ShowMessage")
 	}
 	// TODO: content should be parsed here based on the ID.
 	content := game.Texts.Get(lang.Get(), contentID)
 	content = parser.ParseMessageSyntax(content)
-	w.nextBanner = newBanner(contentID, content, eventID, background, positionType, textAlign, interpreterID, messageStyle)
+	w.nextBanner = newBanner(contentID, content, eventID, background, positionType, textAlign, interpreterID, messageStyle, newVoice(voiceName, waitVoice), newSpeaker(speaker, parser, game))
 }
 
-func (w *Windows) ShowChoices(parser MessageSyntaxParser, game *data.Game, choices []*Choice, interpreterID consts.InterpreterID) {
+// ShowChoices shows the choices.
+//
+// defaultIndex is the index of the highlighted choice, or -1.
+// timeoutFrames is the number of frames until the choice at timeoutIndex is chosen automatically. 0 means no time limit.
+// timeoutIndex can be ChoiceIndexCanceled.
+func (w *Windows) ShowChoices(parser MessageSyntaxParser, game *data.Game, choices []*Choice, defaultIndex int, timeoutFrames int, timeoutIndex int, cancelable bool, interpreterID consts.InterpreterID) {
 	// TODO: w.chosenBalloonWaitingCount should be 0 here!
 	if w.chosenBalloonWaitingCount > 0 {
 		panic("windows: chosenBalloonWaitingCount must be > 0 at ShowChoices")
@@ -217,16 +382,122 @@ func (w *Windows) ShowChoices(parser MessageSyntaxParser, game *data.Game, choic
 	w.choiceBalloons = nil
 	for i, choice := range choices {
 		x := 0
-		y := i * choiceBalloonHeight
+		y := (i % maxChoicesPerPage) * choiceBalloonHeight
 		width := consts.MapWidth
 		balloon := newBalloon(x, y, width, choiceBalloonHeight, choice.ID, choice.Text(parser, game), data.BalloonTypeNormal, interpreterID, game.CreateChoicesMessageStyle(), choice.Checked)
 		w.choiceBalloons = append(w.choiceBalloons, balloon)
 		balloon.open()
 	}
+	w.choices = choices
+
+	w.choicePage = 0
+	if defaultIndex >= 0 {
+		w.choicePage = defaultIndex / maxChoicesPerPage
+	}
+	w.choicePager = nil
+	if len(choices) > maxChoicesPerPage {
+		w.choicePager = newBalloon(0, maxChoicesPerPage*choiceBalloonHeight, consts.MapWidth, choiceBalloonHeight, data.UUID{}, w.choicePagerText(), data.BalloonTypeNormal, interpreterID, game.CreateChoicesMessageStyle(), false)
+		w.choicePager.open()
+	}
+
 	w.chosenIndex = 0
 	w.choosing = true
 	w.choosingInterpreterID = interpreterID
 	w.hasChosenIndex = false
+	w.choiceDefaultIndex = defaultIndex
+	w.choiceTimeoutCount = timeoutFrames
+	w.choiceTimeoutFrames = timeoutFrames
+	w.choiceTimeoutIndex = timeoutIndex
+	w.choiceCancelable = cancelable
+}
+
+func (w *Windows) choicePageNum() int {
+	return (len(w.choiceBalloons) + maxChoicesPerPage - 1) / maxChoicesPerPage
+}
+
+func (w *Windows) choicePagerText() string {
+	return fmt.Sprintf("←　%d / %d　→", w.choicePage+1, w.choicePageNum())
+}
+
+// choiceRows returns the number of the rows of the choices including the pager.
+func (w *Windows) choiceRows() int {
+	if len(w.choiceBalloons) > maxChoicesPerPage {
+		return maxChoicesPerPage + 1
+	}
+	return len(w.choiceBalloons)
+}
+
+func (w *Windows) turnChoicePage(delta int) {
+	n := w.choicePageNum()
+	w.choicePage = (w.choicePage + delta + n) % n
+	w.choicePager.overwriteContent(w.choicePagerText())
+}
+
+// allChoiceBalloons returns the choice balloons and the pager.
+func (w *Windows) allChoiceBalloons() []*balloon {
+	if w.choicePager == nil {
+		return w.choiceBalloons
+	}
+	bs := make([]*balloon, 0, len(w.choiceBalloons)+1)
+	bs = append(bs, w.choiceBalloons...)
+	return append(bs, w.choicePager)
+}
+
+// CancelChoices cancels the choices if possible, and reports whether the choices are canceled.
+func (w *Windows) CancelChoices() bool {
+	if !w.choosing || !w.choiceCancelable || w.chosenBalloonWaitingCount > 0 {
+		return false
+	}
+	if !w.isOpened(0) {
+		return false
+	}
+	w.choose(ChoiceIndexCanceled)
+	return true
+}
+
+func (w *Windows) choiceDisabled(index int) bool {
+	return index < len(w.choices) && w.choices[index].Disabled
+}
+
+func (w *Windows) hasEnabledChoice() bool {
+	for i := range w.choiceBalloons {
+		if !w.choiceDisabled(i) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Windows) choose(index int) {
+	if index != ChoiceIndexCanceled && w.choiceDisabled(index) {
+		return
+	}
+	for i, b := range w.choiceBalloons {
+		if i == index {
+			continue
+		}
+		b.close()
+	}
+	if w.choicePager != nil {
+		w.choicePager.close()
+	}
+	w.chosenIndex = index
+	if index != ChoiceIndexCanceled {
+		// The chosen choice might be on another page when the time is up.
+		w.choicePage = index / maxChoicesPerPage
+		c := w.choiceBalloons[index]
+		id := c.contentID
+		if index < len(w.choices) && w.choices[index].Icon != "" {
+			// The icon is not in the text of the ID. Keep the text as it was shown.
+			id = data.UUID{}
+		}
+		w.addHistory(HistoryEntryTypeChoice, 0, id, c.content, nil)
+	}
+	w.chosenBalloonWaitingCount = chosenBalloonWaitingFrames
+	w.choosing = false
+	w.choosingInterpreterID = 0
+	w.hasChosenIndex = true
+	w.choiceTimeoutCount = 0
 }
 
 // GC closes windows immediately if its interpreter ID is not in the given interpreter ID set.
@@ -247,7 +518,7 @@ func (w *Windows) GC(excludes []consts.InterpreterID) {
 		}
 		b.closeImmediately()
 	}
-	for _, b := range w.choiceBalloons {
+	for _, b := range w.allChoiceBalloons() {
 		if b == nil {
 			continue
 		}
@@ -273,7 +544,7 @@ func (w *Windows) CloseImmediatelyForInterpreter(interpreterID consts.Interprete
 		}
 		b.closeImmediately()
 	}
-	for _, b := range w.choiceBalloons {
+	for _, b := range w.allChoiceBalloons() {
 		if b == nil {
 			continue
 		}
@@ -294,7 +565,7 @@ func (w *Windows) CloseAll() {
 		}
 		b.close()
 	}
-	for _, b := range w.choiceBalloons {
+	for _, b := range w.allChoiceBalloons() {
 		if b == nil {
 			continue
 		}
@@ -349,7 +620,10 @@ func (w *Windows) CanProceed(interpreterID consts.InterpreterID) bool {
 	if !w.isOpened(interpreterID) {
 		return false
 	}
-	return inputTriggered()
+	if inputTriggered() {
+		return true
+	}
+	return w.canProceedAutomatically(interpreterID)
 }
 
 func (w *Windows) isOpened(interpreterID consts.InterpreterID) bool {
@@ -364,7 +638,7 @@ func (w *Windows) isOpened(interpreterID consts.InterpreterID) bool {
 			return true
 		}
 	}
-	for _, b := range w.choiceBalloons {
+	for _, b := range w.allChoiceBalloons() {
 		if b == nil {
 			continue
 		}
@@ -395,7 +669,7 @@ func (w *Windows) IsAnimating(interpreterID consts.InterpreterID) bool {
 			return true
 		}
 	}
-	for _, b := range w.choiceBalloons {
+	for _, b := range w.allChoiceBalloons() {
 		if b == nil {
 			continue
 		}
@@ -433,6 +707,8 @@ func (w *Windows) Update(playerY int, parser MessageSyntaxParser, sceneManager *
 	if w.lastLang == language.Und {
 		w.lastLang = lang.Get()
 	}
+	w.autoMode = sceneManager.AutoModeEnabled()
+	w.skipMode = sceneManager.SkipModeEnabled()
 
 	if w.lastLang != lang.Get() {
 		for _, b := range w.balloons {
@@ -441,12 +717,18 @@ func (w *Windows) Update(playerY int, parser MessageSyntaxParser, sceneManager *
 			}
 			content := sceneManager.Game().Texts.Get(lang.Get(), b.contentID)
 			content = parser.ParseMessageSyntax(content)
+			b.speaker.updateName(parser, sceneManager.Game())
 			b.overwriteContent(content)
 		}
-		for _, b := range w.choiceBalloons {
+		for i, b := range w.choiceBalloons {
 			if b == nil {
 				continue
 			}
+			// Old data doesn't have the choices.
+			if len(w.choices) == len(w.choiceBalloons) {
+				b.overwriteContent(w.choices[i].Text(parser, sceneManager.Game()))
+				continue
+			}
 			content := sceneManager.Game().Texts.Get(lang.Get(), b.contentID)
 			content = parser.ParseMessageSyntax(content)
 			b.overwriteContent(content)
@@ -454,8 +736,10 @@ func (w *Windows) Update(playerY int, parser MessageSyntaxParser, sceneManager *
 		if w.banner != nil {
 			content := sceneManager.Game().Texts.Get(lang.Get(), w.banner.contentID)
 			content = parser.ParseMessageSyntax(content)
+			w.banner.speaker.updateName(parser, sceneManager.Game())
 			w.banner.overwriteContent(content)
 		}
+		w.updateHistoryLanguage(parser, sceneManager.Game())
 		w.lastLang = lang.Get()
 	}
 
@@ -465,18 +749,27 @@ func (w *Windows) Update(playerY int, parser MessageSyntaxParser, sceneManager *
 		if w.nextBalloon != nil && !w.IsAnimating(0) && !w.isOpened(0) {
 			w.balloons = []*balloon{w.nextBalloon}
 			w.balloons[0].open()
+			w.balloons[0].read = w.markRead(sceneManager, w.nextBalloon.contentID)
+			w.addHistory(HistoryEntryTypeBalloon, w.nextBalloon.eventID, w.nextBalloon.contentID, w.nextBalloon.content, w.nextBalloon.speaker)
 			w.nextBalloon = nil
 		}
 		if w.nextBanner != nil && !w.IsAnimating(0) && !w.isOpened(0) {
 			w.banner = w.nextBanner
 			w.banner.open()
+			w.banner.read = w.markRead(sceneManager, w.banner.contentID)
+			w.addHistory(HistoryEntryTypeMessage, w.banner.eventID, w.banner.contentID, w.banner.content, w.banner.speaker)
 			w.nextBanner = nil
 		}
 	}
+	if w.choosing && w.isOpened(0) && w.choiceTimeoutCount > 1 {
+		w.choiceTimeoutCount--
+	}
 	if w.chosenBalloonWaitingCount > 0 {
 		w.chosenBalloonWaitingCount--
 		if w.chosenBalloonWaitingCount == 0 {
-			w.choiceBalloons[w.chosenIndex].close()
+			if w.chosenIndex != ChoiceIndexCanceled {
+				w.choiceBalloons[w.chosenIndex].close()
+			}
 			for _, b := range w.balloons {
 				if b == nil {
 					continue
@@ -487,35 +780,46 @@ func (w *Windows) Update(playerY int, parser MessageSyntaxParser, sceneManager *
 				w.banner.close()
 			}
 		}
+	} else if w.choosing && w.isOpened(0) && w.choiceTimeoutCount == 1 {
+		// The time is up.
+		idx := w.choiceTimeoutIndex
+		if w.choiceDisabled(idx) {
+			idx = ChoiceIndexCanceled
+		}
+		w.choose(idx)
+	} else if w.choosing && w.isOpened(0) && w.choiceTimeoutFrames == 0 && !w.choiceCancelable && !w.hasEnabledChoice() {
+		// Nothing can be chosen. Cancel the choices so that the game doesn't get stuck.
+		w.choose(ChoiceIndexCanceled)
 	} else if w.choosing && w.isOpened(0) && inputTriggered() {
-		_, h := sceneManager.Size()
+		sw, h := sceneManager.Size()
 		ymax := h / consts.TileScale
-		ymin := ymax - len(w.choiceBalloons)*choiceBalloonHeight
-		_, y := input.Position()
+		ymin := ymax - w.choiceRows()*choiceBalloonHeight
+		x, y := input.Position()
 		y += sceneManager.BottomOffset()
 		y /= consts.TileScale
 		if y < ymin || ymax <= y {
 			return
 		}
-		// Close regular balloons
-		w.chosenIndex = (y - ymin) / choiceBalloonHeight
-		for i, b := range w.choiceBalloons {
-			if i == w.chosenIndex {
-				continue
+		row := (y - ymin) / choiceBalloonHeight
+		if w.choicePager != nil && row == maxChoicesPerPage {
+			x = x/consts.TileScale - (sw/consts.TileScale-consts.MapWidth)/2
+			if x < consts.MapWidth/2 {
+				w.turnChoicePage(-1)
+			} else {
+				w.turnChoicePage(1)
 			}
-			b.close()
+		} else if idx := w.choicePage*maxChoicesPerPage + row; idx < len(w.choiceBalloons) {
+			w.choose(idx)
 		}
-		w.chosenBalloonWaitingCount = chosenBalloonWaitingFrames
-		w.choosing = false
-		w.choosingInterpreterID = 0
-		w.hasChosenIndex = true
 	}
 	for i, b := range w.balloons {
 		if b == nil {
 			continue
 		}
 		b.update(w.findCharacterByEventID(characters, b.eventID))
-		if b.isAnimating() && inputTriggered() {
+		if b.isAnimating() && w.skipMode && b.read {
+			b.skipReadText()
+		} else if b.isAnimating() && inputTriggered() {
 			b.trySkipTypingAnim()
 		} else if b.isClosed() {
 			w.balloons[i] = nil
@@ -530,14 +834,23 @@ func (w *Windows) Update(playerY int, parser MessageSyntaxParser, sceneManager *
 			w.choiceBalloons[i] = nil
 		}
 	}
+	if w.choicePager != nil {
+		w.choicePager.update(nil)
+		if w.choicePager.isClosed() {
+			w.choicePager = nil
+		}
+	}
 	if w.banner != nil {
 		w.banner.update(playerY, w.findCharacterByEventID(characters, w.banner.eventID))
-		if w.banner.isAnimating() && inputTriggered() {
+		if w.banner.isAnimating() && w.skipMode && w.banner.read {
+			w.banner.skipReadText()
+		} else if w.banner.isAnimating() && inputTriggered() {
 			w.banner.trySkipTypingAnim()
 		} else if w.banner.isClosed() {
 			w.banner = nil
 		}
 	}
+	w.updateAutoAdvance()
 }
 
 func (w *Windows) Draw(screen *ebiten.Image, characters []*character.Character, offsetX, offsetY, windowOffsetY int) {
@@ -550,12 +863,33 @@ func (w *Windows) Draw(screen *ebiten.Image, characters []*character.Character,
 	if w.banner != nil {
 		w.banner.draw(screen, offsetX, 0)
 	}
-	_, sh := screen.Size()
-	for _, b := range w.choiceBalloons {
+	sw, sh := screen.Size()
+	y := sh/consts.TileScale - windowOffsetY - w.choiceRows()*choiceBalloonHeight
+	for i, b := range w.choiceBalloons {
 		if b == nil {
 			continue
 		}
-		b.draw(screen, nil, offsetX, sh/consts.TileScale-windowOffsetY-len(w.choiceBalloons)*choiceBalloonHeight)
+		if i/maxChoicesPerPage != w.choicePage {
+			continue
+		}
+		// Choices other than the default choice are dimmed so that the default choice is highlighted.
+		b.dimmed = w.choosing && w.choiceDefaultIndex >= 0 && i != w.choiceDefaultIndex
+		if w.choiceDisabled(i) {
+			b.dimmed = true
+		}
+		b.draw(screen, nil, offsetX, y)
+	}
+	if w.choicePager != nil {
+		w.choicePager.draw(screen, nil, offsetX, y)
 	}
 
+	if w.choosing && w.choiceTimeoutCount > 0 && w.isOpened(0) {
+		x := (sw/consts.TileScale-consts.MapWidth)/2 + offsetX
+		rate := float64(w.choiceTimeoutCount) / float64(w.choiceTimeoutFrames)
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Scale(consts.MapWidth*rate, choiceTimerHeight)
+		op.GeoM.Translate(float64(x), float64(y-choiceTimerHeight))
+		op.GeoM.Scale(consts.TileScale, consts.TileScale)
+		screen.DrawImage(choiceTimerImage, op)
+	}
 }
@@ -262,3 +262,20 @@ func RespondAsset(id int, success bool, data []uint8) (err error) {
 	theGame.RespondAsset(id, success, d)
 	return nil
 }
+
+func RespondInputText(id int, success bool, text string) (err error) {
+	<-startCalled
+
+	defer func() {
+		if r := recover(); r != nil {
+			ok := false
+			err, ok = r.(error)
+			if !ok {
+				err = fmt.Errorf("error at RespondInputText: %v", err)
+			}
+		}
+	}()
+
+	theGame.RespondInputText(id, success, text)
+	return nil
+}